@@ -0,0 +1,35 @@
+package responder
+
+// Ask creates a responder from the prompt, choices and options, gets a
+// response from the user and returns the response and its description. Any
+// error from constructing the responder is returned, as is any error from
+// getting the response. This is intended for simple, one-off questions.
+func Ask(
+	prompt string,
+	choices map[rune]string,
+	opts ...RespOptFunc,
+) (rune, string, error) {
+	r, err := New(prompt, choices, opts...)
+	if err != nil {
+		return 0, "", err
+	}
+
+	resp, err := r.GetResponse()
+
+	return resp, r.description(resp), err
+}
+
+// AskOrDie behaves as Ask but if there is an error constructing the
+// responder it will panic and if there is an error getting the response it
+// will print it and exit with status 1.
+func AskOrDie(
+	prompt string,
+	choices map[rune]string,
+	opts ...RespOptFunc,
+) (rune, string) {
+	r := NewOrPanic(prompt, choices, opts...)
+
+	resp := r.GetResponseOrDie()
+
+	return resp, r.description(resp)
+}
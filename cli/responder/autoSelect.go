@@ -0,0 +1,84 @@
+package responder
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// SetAutoSelectSingle makes GetResponse return the response immediately,
+// without reading any input, when there is only one valid response for
+// the call (see GetResponseAmong). If showNotice is true the prompt is
+// printed followed by the chosen response so that the user can see what
+// was selected. Without this option the user is prompted as usual, which
+// can be used to get an explicit confirmation.
+func SetAutoSelectSingle(showNotice bool) RespOptFunc {
+	return func(r *R) error {
+		r.autoSelectSingle = true
+		r.autoSelectNotice = showNotice
+
+		return nil
+	}
+}
+
+// GetResponseAmong behaves as GetResponseIndent but only the given
+// responses are valid for this call. This allows the choices offered to be
+// narrowed according to earlier answers. Each of the responses must be in
+// the responder's list of valid responses and at least one must be given.
+// If the default is not among them then there is no default for this
+// call.
+func (r R) GetResponseAmong(allowed []rune, first, second int) (rune, error) {
+	if len(allowed) == 0 {
+		return unicode.ReplacementChar,
+			fmt.Errorf("GetResponseAmong: no responses were given")
+	}
+
+	r, err := r.resolveDefault()
+	if err != nil {
+		return unicode.ReplacementChar, err
+	}
+
+	resps := make(map[rune]string, len(allowed))
+	for _, c := range allowed {
+		desc, ok := r.validResps[c]
+		if !ok {
+			return unicode.ReplacementChar,
+				fmt.Errorf("GetResponseAmong: the response (%c) is not"+
+					" in the list of valid responses",
+					c)
+		}
+		resps[c] = desc
+	}
+
+	r.validResps = resps
+	r.compiled = nil
+	r.hasInterDflt = false
+	r.hasNonInterDflt = false
+	if _, ok := resps[r.dflt]; !ok {
+		r.hasDflt = false
+	}
+
+	return r.GetResponseIndent(first, second)
+}
+
+// autoSelect returns the single valid response and true if the response
+// should be selected without prompting, otherwise it returns false. If a
+// notice is to be shown it is printed with the given indent.
+func (r R) autoSelect(first int) (rune, bool) {
+	if !r.autoSelectSingle || len(r.validResps) != 1 {
+		return unicode.ReplacementChar, false
+	}
+
+	var resp rune
+	for c := range r.validResps {
+		resp = c
+	}
+
+	if r.autoSelectNotice {
+		fmt.Fprintf(r.out, "%s%s: %c (the only valid response)\n",
+			strings.Repeat(" ", first), r.promptValue(), resp)
+	}
+	r.record("answer", string(resp))
+
+	return resp, true
+}
@@ -0,0 +1,151 @@
+package responder
+
+import "fmt"
+
+// BatchResponder wraps an R and adds extra "apply to all" responses. Once
+// the user has chosen one of these, every subsequent call to GetResponse
+// (or any of the other Responder methods) will return the corresponding
+// response without prompting the user until the Reset method is called.
+//
+// This is useful where the same question is asked repeatedly, for instance
+// once for each file in a list, and the user might want to give the same
+// answer for all the remaining files.
+type BatchResponder struct {
+	r *R
+
+	allResps map[rune]rune
+
+	decided  bool
+	decision rune
+}
+
+// NewBatchResponder creates a BatchResponder. The allResps map is keyed by
+// the extra "apply to all" responses; the values are the responses (which
+// must be in the responses map) that will be returned once the
+// corresponding key has been chosen. The "apply to all" responses will be
+// added to the set of valid responses with a description derived from that
+// of the response they map to. The same rules apply to these extra
+// responses as to any other.
+func NewBatchResponder(
+	prompt string,
+	responses map[rune]string,
+	allResps map[rune]rune,
+	opts ...RespOptFunc,
+) (*BatchResponder, error) {
+	if len(allResps) == 0 {
+		return nil,
+			fmt.Errorf("there must be at least one 'apply to all' response")
+	}
+
+	combined := make(map[rune]string, len(responses)+len(allResps))
+	for k, v := range responses {
+		combined[k] = v
+	}
+
+	for k, v := range allResps {
+		if _, ok := responses[k]; ok {
+			return nil,
+				fmt.Errorf(
+					"the 'apply to all' response '%c' is already"+
+						" in the list of valid responses",
+					k)
+		}
+
+		desc, ok := responses[v]
+		if !ok {
+			return nil,
+				fmt.Errorf(
+					"the 'apply to all' response '%c' maps to '%c'"+
+						" which is not in the list of valid responses",
+					k, v)
+		}
+
+		combined[k] = desc + " (and apply this to all the rest)"
+	}
+
+	r, err := New(prompt, combined, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BatchResponder{
+		r:        r,
+		allResps: allResps,
+	}, nil
+}
+
+// NewBatchResponderOrPanic creates a new BatchResponder and panics if there
+// are any errors
+func NewBatchResponderOrPanic(
+	prompt string,
+	responses map[rune]string,
+	allResps map[rune]rune,
+	opts ...RespOptFunc,
+) *BatchResponder {
+	br, err := NewBatchResponder(prompt, responses, allResps, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return br
+}
+
+// Reset clears any "apply to all" decision so that the next call to
+// GetResponse will prompt the user again.
+func (br *BatchResponder) Reset() {
+	br.decided = false
+	br.decision = 0
+}
+
+// Decision returns the response that will be returned for all subsequent
+// calls and true if the user has chosen one of the "apply to all"
+// responses. Otherwise it returns false.
+func (br *BatchResponder) Decision() (rune, bool) {
+	return br.decision, br.decided
+}
+
+// GetResponse returns the "apply to all" decision if one has been made,
+// otherwise it calls the GetResponse method on the underlying R. If the
+// user chooses one of the "apply to all" responses then the response it
+// maps to is returned and is recorded for later calls.
+func (br *BatchResponder) GetResponse() (rune, error) {
+	return br.GetResponseIndent(br.r.indentFirst, br.r.indent)
+}
+
+// GetResponseOrDie calls GetResponse to get the response but if there is an
+// error it will print it and exit with status 1.
+func (br *BatchResponder) GetResponseOrDie() rune {
+	return br.GetResponseIndentOrDie(br.r.indentFirst, br.r.indent)
+}
+
+// GetResponseIndent behaves as GetResponse but the indents are taken from
+// the parameters rather than the responder.
+func (br *BatchResponder) GetResponseIndent(first, second int) (rune, error) {
+	if br.decided {
+		return br.decision, nil
+	}
+
+	resp, err := br.r.GetResponseIndent(first, second)
+	if err != nil {
+		return resp, err
+	}
+
+	if target, ok := br.allResps[resp]; ok {
+		br.decided = true
+		br.decision = target
+
+		return target, nil
+	}
+
+	return resp, nil
+}
+
+// GetResponseIndentOrDie calls GetResponseIndent to get the response but if
+// there is an error it will print it and exit with status 1.
+func (br *BatchResponder) GetResponseIndentOrDie(first, second int) rune {
+	resp, err := br.GetResponseIndent(first, second)
+	if err != nil {
+		br.r.reportErrAndExit(err)
+	}
+
+	return resp
+}
@@ -0,0 +1,99 @@
+package responder
+
+import (
+	"time"
+	"unicode"
+)
+
+// cancelPollInterval is how often GetResponseWithCancel checks whether the
+// done channel has been closed while waiting for input
+const cancelPollInterval = 100 * time.Millisecond
+
+// GetResponseWithCancel behaves as GetResponseIndent but will stop waiting
+// for a response once the done channel is closed, returning the unicode
+// ReplacementChar with the ErrCancelled error. This is a simpler
+// alternative to using a context where the caller already has a done
+// channel.
+//
+// The response is read in a separate goroutine. If the input can be polled
+// (it must be a file, see SetInput, and polling must be supported on the
+// platform) that goroutine checks the done channel while waiting for
+// input; once the channel is closed it restores the terminal and finishes
+// before GetResponseWithCancel returns. If a response was read just before
+// the channel was closed then that response is returned instead.
+//
+// If the input cannot be polled then GetResponseWithCancel returns as soon
+// as the channel is closed but the goroutine is left waiting for the next
+// rune. It will finish once that rune has been read, or the end of the
+// input is reached, but the rune is lost; the caller should take care not
+// to read from the same input while the goroutine is still running.
+func (r R) GetResponseWithCancel(
+	done <-chan struct{}, first, second int,
+) (rune, error) {
+	select {
+	case <-done:
+		return unicode.ReplacementChar, ErrCancelled
+	default:
+	}
+
+	r.done = done
+	pollable := r.inputPollable()
+
+	type result struct {
+		resp rune
+		err  error
+	}
+	resCh := make(chan result, 1)
+
+	go func() {
+		resp, err := r.GetResponseIndent(first, second)
+		resCh <- result{resp: resp, err: err}
+	}()
+
+	select {
+	case res := <-resCh:
+		return res.resp, res.err
+	case <-done:
+	}
+
+	if pollable {
+		res := <-resCh
+		return res.resp, res.err
+	}
+
+	return unicode.ReplacementChar, ErrCancelled
+}
+
+// inputPollable reports whether the input can be polled to see if there is
+// input waiting. This needs a file descriptor and a platform which
+// supports polling it.
+func (r R) inputPollable() bool {
+	if r.fd == noFD {
+		return false
+	}
+
+	_, err := waitForInput(r.fd, 0)
+
+	return err == nil
+}
+
+// waitForDone waits until there is input to read. It returns ErrCancelled
+// if the done channel is closed first. If there is no done channel or the
+// input cannot be polled it returns immediately.
+func (r R) waitForDone() error {
+	if r.done == nil || r.fd == noFD || r.rdr.Buffered() > 0 {
+		return nil
+	}
+
+	for {
+		ready, err := waitForInput(r.fd, cancelPollInterval)
+		select {
+		case <-r.done:
+			return ErrCancelled
+		default:
+		}
+		if err != nil || ready {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package responder
+
+import (
+	"fmt"
+	"io"
+)
+
+// SetCaptureRaw makes the responder append every byte read from its input
+// to the slice. This is a diagnostic aid which can help to find out why a
+// key, such as an arrow or a function key, is not being recognised; the
+// escape sequences sent by terminals vary. It does not change the
+// responses returned.
+//
+// The bytes are captured as they are read from the input and the input is
+// buffered, so the slice may hold bytes which have been read ahead but not
+// yet used. On a terminal in raw mode each read returns just the bytes
+// sent by the key (or keys) pressed. The slice is not safe for concurrent
+// use and should not be examined while a response is being read.
+//
+// The capture applies to the reader set by SetInput or SetFile regardless
+// of the order in which the options are given.
+func SetCaptureRaw(buf *[]byte) RespOptFunc {
+	return func(r *R) error {
+		if buf == nil {
+			return fmt.Errorf("SetCaptureRaw: the buffer must not be nil")
+		}
+
+		r.captureRaw = buf
+		r.rdr = r.newReader(r.input)
+
+		return nil
+	}
+}
+
+// captureReader is an io.Reader which appends every byte read to a slice
+type captureReader struct {
+	rdr io.Reader
+	buf *[]byte
+}
+
+// Read reads from the underlying reader and records the bytes read
+func (cr captureReader) Read(p []byte) (int, error) {
+	n, err := cr.rdr.Read(p)
+	*cr.buf = append(*cr.buf, p[:n]...)
+
+	return n, err
+}
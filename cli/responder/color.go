@@ -0,0 +1,57 @@
+package responder
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	colorStartFmt = "\x1b[%sm"
+	colorReset    = "\x1b[0m"
+
+	rejectedColor = "31"
+)
+
+// SetResponseColor sets the colour in which the given response is shown in
+// the list of valid responses. This can be used to draw attention to a
+// response with serious consequences, for instance showing 'd' (delete) in
+// red. The code is an ANSI SGR parameter string such as "31" (red) or
+// "1;31" (bold red). The colour is only shown if the output is a terminal.
+//
+// The response must be in the list of valid responses. This option may be
+// given more than once to colour several responses.
+func SetResponseColor(c rune, code string) RespOptFunc {
+	return func(r *R) error {
+		if _, ok := r.validResps[c]; !ok {
+			return fmt.Errorf(
+				"SetResponseColor: the response (%c) is not"+
+					" in the list of valid responses",
+				c)
+		}
+		if code == "" ||
+			strings.Trim(code, "0123456789;") != "" {
+			return fmt.Errorf(
+				"SetResponseColor: bad colour code (%q):"+
+					" it must be a non-empty string of digits and semicolons",
+				code)
+		}
+
+		if r.respColors == nil {
+			r.respColors = make(map[rune]string)
+		}
+		r.respColors[c] = code
+
+		return nil
+	}
+}
+
+// respText returns the response as a string, wrapped in its colour if one
+// has been set and the output is a terminal
+func (r R) respText(c rune) string {
+	code, ok := r.respColors[c]
+	if !ok || !r.outputIsTerminal() {
+		return string(c)
+	}
+
+	return fmt.Sprintf(colorStartFmt, code) + string(c) + colorReset
+}
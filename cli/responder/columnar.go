@@ -0,0 +1,157 @@
+package responder
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+const (
+	// columnarMinResps is the number of responses above which the
+	// columnar layout is used (see SetColumnarPrompt)
+	columnarMinResps = 5
+	// columnGap is the number of spaces between the columns
+	columnGap = 3
+)
+
+// SetColumnarPrompt makes the responder show the valid responses and their
+// descriptions in aligned columns, above the prompt, when there are more
+// than 5 responses. The columns are fitted to the width of the terminal
+// and the prompt line is then shown without the list of responses. This
+// keeps a large set of choices readable. The default response is shown in
+// brackets.
+//
+// The usual one-line list is shown if the output is not a terminal, if the
+// terminal is too narrow for at least two columns or if a prompt template
+// has been set (see SetPromptTemplate).
+func SetColumnarPrompt() RespOptFunc {
+	return func(r *R) error {
+		r.columnar = true
+
+		return nil
+	}
+}
+
+// columnCell is a single entry in the columnar layout
+type columnCell struct {
+	text  string
+	width int
+}
+
+// columnCells returns the entries to be shown in the columnar layout. The
+// responder's default should already have been resolved.
+func (r R) columnCells() []columnCell {
+	cells := make([]columnCell, 0, len(r.validResps)+1)
+
+	add := func(keyFmt string, c rune, text, desc string) {
+		cells = append(cells, columnCell{
+			text: fmt.Sprintf(keyFmt, text) + " " + desc,
+			width: displayWidth(
+				fmt.Sprintf(keyFmt, string(c)) + " " + desc),
+		})
+	}
+
+	for _, c := range r.getSortedValidResponses() {
+		keyFmt := " %s "
+		if r.hasDflt && c == r.dflt {
+			keyFmt = "[%s]"
+		}
+		add(keyFmt, c, r.respText(c), r.description(c))
+	}
+	if r.helpEnabled() {
+		add(" %s ", helpRune, string(helpRune), "show help")
+	}
+
+	return cells
+}
+
+// maxCellWidth returns the width of the widest cell
+func maxCellWidth(cells []columnCell) int {
+	width := 0
+	for _, c := range cells {
+		if c.width > width {
+			width = c.width
+		}
+	}
+
+	return width
+}
+
+// columnCount returns the number of columns that the cells will fit into
+// in the given width
+func columnCount(cells []columnCell, width int) int {
+	return (width + columnGap) / (maxCellWidth(cells) + columnGap)
+}
+
+// outputWidth returns the width of the terminal that the output is written
+// to and true, or false if the output is not a terminal
+func (r R) outputWidth() (int, bool) {
+	f, ok := r.out.(interface{ Fd() uintptr })
+	if !ok {
+		return 0, false
+	}
+
+	width, _, err := term.GetSize(int(f.Fd()))
+	if err != nil || width <= 0 {
+		return 0, false
+	}
+
+	return width, true
+}
+
+// useColumns reports whether the responses should be shown in columns.
+// The responder's default should already have been resolved.
+func (r R) useColumns() bool {
+	if !r.columnar ||
+		r.promptTmpl != "" ||
+		len(r.validResps) <= columnarMinResps {
+		return false
+	}
+
+	width, ok := r.outputWidth()
+	if !ok {
+		return false
+	}
+
+	return columnCount(r.columnCells(), width) >= 2
+}
+
+// printColumns prints the responses in columns, each line starting with
+// the prefix, if the columnar layout is to be used. The responder's
+// default should already have been resolved.
+func (r R) printColumns(prefix string) {
+	if !r.useColumns() {
+		return
+	}
+
+	width, _ := r.outputWidth()
+	cells := r.columnCells()
+
+	cols := columnCount(cells, width-displayWidth(prefix))
+	if cols < 1 {
+		cols = 1
+	}
+	rows := (len(cells) + cols - 1) / cols
+
+	cellWidth := maxCellWidth(cells)
+
+	for row := 0; row < rows; row++ {
+		var b strings.Builder
+		b.WriteString(prefix)
+		for col := 0; col < cols; col++ {
+			i := col*rows + row
+			if i >= len(cells) {
+				break
+			}
+			if col > 0 {
+				b.WriteString(strings.Repeat(" ", columnGap))
+			}
+			b.WriteString(cells[i].text)
+			if col < cols-1 && i+rows < len(cells) {
+				b.WriteString(strings.Repeat(" ", cellWidth-cells[i].width))
+			}
+		}
+		fmt.Fprintln(r.out, b.String())
+	}
+}
@@ -0,0 +1,48 @@
+package responder
+
+// compiledResps holds the cached list of valid responses along with the
+// settings that it depends on
+type compiledResps struct {
+	hasDflt     bool
+	dflt        rune
+	helpEnabled bool
+	list        string
+}
+
+// Compile builds the list of valid responses shown in the prompt and
+// caches it so that it need not be rebuilt each time the prompt is
+// printed. This is useful when the same question is asked many times, for
+// instance once per record in a review tool.
+//
+// The cached list is only used while the default response and whether help
+// is shown are the same as when Compile was called; if either of these
+// changes (for instance because the default is set by SetDefaultFunc) then
+// the list is built as usual. The cache is shared by any copies of the
+// responder made after Compile is called.
+func (r *R) Compile() {
+	// the default function is not called here as it may have side effects
+	rd := *r
+	rd.dfltFunc = nil
+	rd = rd.resolveModeDefault()
+
+	r.compiled = &compiledResps{
+		hasDflt:     rd.hasDflt,
+		dflt:        rd.dflt,
+		helpEnabled: rd.helpEnabled(),
+		list:        rd.buildResponsesList(),
+	}
+}
+
+// compiledList returns the cached list of valid responses and true if it
+// can be used. The responder's default should already have been resolved.
+func (r R) compiledList() (string, bool) {
+	c := r.compiled
+	if c == nil ||
+		c.hasDflt != r.hasDflt ||
+		(c.hasDflt && c.dflt != r.dflt) ||
+		c.helpEnabled != r.helpEnabled() {
+		return "", false
+	}
+
+	return c.list, true
+}
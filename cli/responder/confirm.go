@@ -0,0 +1,76 @@
+package responder
+
+import "fmt"
+
+// ConfirmResult describes the user's answer to a ConfirmAll question
+type ConfirmResult int
+
+const (
+	// ConfirmNo means that the user answered no for this item
+	ConfirmNo ConfirmResult = iota
+	// ConfirmYes means that the user answered yes for this item
+	ConfirmYes
+	// ConfirmNoAll means that the user answered no for this and all
+	// subsequent items
+	ConfirmNoAll
+	// ConfirmYesAll means that the user answered yes for this and all
+	// subsequent items
+	ConfirmYesAll
+)
+
+// String returns a string describing the ConfirmResult
+func (cr ConfirmResult) String() string {
+	switch cr {
+	case ConfirmNo:
+		return "no"
+	case ConfirmYes:
+		return "yes"
+	case ConfirmNoAll:
+		return "no to all"
+	case ConfirmYesAll:
+		return "yes to all"
+	}
+
+	return fmt.Sprintf("unknown ConfirmResult: %d", int(cr))
+}
+
+// confirmAllResps maps the ConfirmAll responses to their results
+var confirmAllResps = map[rune]ConfirmResult{
+	'y': ConfirmYes,
+	'n': ConfirmNo,
+	'a': ConfirmYesAll,
+	'd': ConfirmNoAll,
+}
+
+// ConfirmAll asks the user a yes/no question which also allows the user to
+// give the same answer for all the remaining items. The responses are:
+//
+//	y  yes
+//	n  no
+//	a  yes to all the rest
+//	d  no to all the rest (decline all)
+//
+// The options are applied to the responder as for New; any error from New is
+// returned. Note that ConfirmAll does not remember the answer: a caller
+// which receives ConfirmYesAll or ConfirmNoAll should not ask again. See
+// BatchResponder for a responder which does remember.
+func ConfirmAll(prompt string, opts ...RespOptFunc) (ConfirmResult, error) {
+	r, err := New(prompt,
+		map[rune]string{
+			'y': "yes",
+			'n': "no",
+			'a': "yes to all the rest",
+			'd': "no to all the rest (decline all)",
+		},
+		opts...)
+	if err != nil {
+		return ConfirmNo, err
+	}
+
+	resp, err := r.GetResponse()
+	if err != nil {
+		return ConfirmNo, err
+	}
+
+	return confirmAllResps[resp], nil
+}
@@ -0,0 +1,42 @@
+package responder
+
+import (
+	"fmt"
+	"net"
+)
+
+// NewConnResponder creates a responder which writes its prompts to the
+// connection and reads the responses from it. This allows a remote
+// operator to answer the prompts, for instance over a Unix domain socket.
+// Any error messages are also written to the connection.
+//
+// Each response is read as a line (see SetLineMode) so the operator should
+// end each answer with a newline; this means that a single character
+// followed by a newline is taken as that response. No attempt is made to
+// put the connection into raw mode. The responses are checked against the
+// valid responses as usual.
+//
+// The responses and options are checked as for the New function; the
+// options are applied after the input and output have been set.
+func NewConnResponder(
+	conn net.Conn,
+	prompt string,
+	responses map[rune]string,
+	opts ...RespOptFunc,
+) (*R, error) {
+	if conn == nil {
+		return nil, fmt.Errorf("the connection must not be nil")
+	}
+
+	connOpts := []RespOptFunc{
+		SetInput(conn),
+		SetOutput(conn),
+		SetLineMode(),
+		func(r *R) error {
+			r.errOut = conn
+			return nil
+		},
+	}
+
+	return New(prompt, responses, append(connOpts, opts...)...)
+}
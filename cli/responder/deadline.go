@@ -0,0 +1,68 @@
+package responder
+
+import (
+	"errors"
+	"time"
+	"unicode"
+)
+
+// GetResponseWithTimeout behaves as GetResponseIndent but will stop waiting
+// for a response after the timeout has passed. See GetResponseDeadline for
+// details.
+func (r R) GetResponseWithTimeout(
+	d time.Duration, first, second int,
+) (rune, bool, error) {
+	return r.GetResponseDeadline(time.Now().Add(d), first, second)
+}
+
+// GetResponseDeadline behaves as GetResponseIndent but will stop waiting
+// for a response once the deadline has passed. This is useful where a
+// single deadline applies to a sequence of prompts. The bool returned
+// reports whether the deadline passed before a response was read. If it
+// did then the default response is returned if one has been set,
+// otherwise the unicode ReplacementChar is returned with the ErrTimedOut
+// error.
+//
+// The deadline is only applied when reading from a terminal (or some other
+// file descriptor that can be polled for input). Input from any other
+// reader (see SetInput) is read without a deadline.
+func (r R) GetResponseDeadline(
+	t time.Time, first, second int,
+) (rune, bool, error) {
+	r.deadline = t
+
+	resp, err := r.GetResponseIndent(first, second)
+	if !errors.Is(err, ErrTimedOut) {
+		return resp, false, err
+	}
+
+	dflt, ok, dfltErr := r.Default()
+	if dfltErr != nil {
+		return unicode.ReplacementChar, true, dfltErr
+	}
+	if ok {
+		return dflt, true, nil
+	}
+
+	return unicode.ReplacementChar, true, err
+}
+
+// waitForDeadline waits until there is input to read or the deadline has
+// passed. It returns ErrTimedOut if the deadline passed first.
+func (r R) waitForDeadline() error {
+	if r.deadline.IsZero() || r.fd == noFD || r.rdr.Buffered() > 0 {
+		return nil
+	}
+
+	timeout := time.Until(r.deadline)
+	if timeout < 0 {
+		timeout = 0
+	}
+
+	ready, err := waitForInput(r.fd, timeout)
+	if err == nil && !ready {
+		return ErrTimedOut
+	}
+
+	return nil
+}
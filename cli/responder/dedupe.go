@@ -0,0 +1,40 @@
+package responder
+
+import "fmt"
+
+// SetDedupeErrors makes the responder collapse consecutive identical error
+// messages. Rather than repeating the same message each time the user
+// presses the same wrong key the message is followed by a count of the
+// number of times it has been seen, for instance "bad response: x (x3)".
+// The count is reset when a different error is seen and each time a
+// response is asked for.
+func SetDedupeErrors() RespOptFunc {
+	return func(r *R) error {
+		r.dedupeErrors = true
+
+		return nil
+	}
+}
+
+// errDeduper tracks repeated error messages
+type errDeduper struct {
+	lastMsg string
+	count   int
+}
+
+// dedupe returns the error to report. If the error's message is the same
+// as the last one then the error is annotated with the number of times it
+// has been seen in succession.
+func (ed *errDeduper) dedupe(err error) error {
+	msg := err.Error()
+	if msg != ed.lastMsg {
+		ed.lastMsg = msg
+		ed.count = 1
+
+		return err
+	}
+
+	ed.count++
+
+	return fmt.Errorf("%w (x%d)", err, ed.count)
+}
@@ -0,0 +1,36 @@
+package responder
+
+// PromptInfo describes a prompt independently of how it is shown. It
+// gives everything needed to present the same choice in some other way,
+// for instance in a GUI.
+type PromptInfo struct {
+	// Prompt is the text of the prompt, without the list of responses
+	Prompt string
+	// Responses holds the valid responses and their descriptions, sorted
+	// by response
+	Responses []Response
+	// Default is the default response. It is only meaningful if
+	// HasDefault is true
+	Default    rune
+	HasDefault bool
+	// HelpEnabled is true if the user can ask for help
+	HelpEnabled bool
+}
+
+// Describe returns a description of the prompt. The default is resolved as
+// it would be when the prompt is shown; if the default cannot be resolved
+// then no default is reported.
+func (r R) Describe() PromptInfo {
+	r, err := r.resolveDefault()
+	if err != nil {
+		r.hasDflt = false
+	}
+
+	return PromptInfo{
+		Prompt:      r.promptValue(),
+		Responses:   r.SortedResponses(),
+		Default:     r.dflt,
+		HasDefault:  r.hasDflt,
+		HelpEnabled: r.helpEnabled(),
+	}
+}
@@ -4,5 +4,20 @@ the terminal. The terminal device will be put into a raw mode so that you can
 read single characters. The package offers a standard help feature and allows
 the caller to specify default values for the prompted value. The value
 entered will be checked against the list of valid entries.
+
+The terminal is put into raw mode using the golang.org/x/term package which
+supports both Unix-like systems and the Windows console. On Windows the
+console handle for standard input is used in place of a file descriptor.
+
+There is no menu mode: the responder prints a single prompt line and reads
+a single key (or a line, see SetLineMode). Features which need the choices
+to be drawn at known places on the screen, such as selecting a response
+with a mouse click, are not supported.
+
+A responder cannot be changed once it has been made: its prompt, responses,
+default and indents are all set by the options given to New. There is
+therefore no need to save and restore its settings. A multi-step flow which
+lets the user go back a step can simply keep the responder used for the
+earlier step and ask again.
 */
 package responder
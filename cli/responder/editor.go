@@ -0,0 +1,102 @@
+package responder
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+const dfltEditor = "vi"
+
+// SetEditorResponse sets a response which, when chosen, will cause the
+// user's editor to be run on an empty temporary file. Once the editor exits
+// the contents of the file are stored in the result and the response is
+// returned as usual. The editor is taken from the VISUAL environment
+// variable or, if that is not set, from EDITOR. If neither is set then "vi"
+// is used. The editor writes to the responder's output if that is a
+// terminal and to standard output otherwise.
+//
+// The response must be in the list of valid responses and the result must
+// not be nil.
+func SetEditorResponse(c rune, result *string) RespOptFunc {
+	return func(r *R) error {
+		if _, ok := r.validResps[c]; !ok {
+			return fmt.Errorf(
+				"SetEditorResponse: the response (%c) is not"+
+					" in the list of valid responses",
+				c)
+		}
+		if result == nil {
+			return fmt.Errorf(
+				"SetEditorResponse: the result must not be nil")
+		}
+
+		r.editResp = c
+		r.hasEditResp = true
+		r.editResult = result
+
+		return nil
+	}
+}
+
+// editorCmd returns the editor command and any arguments
+func editorCmd() []string {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	parts := strings.Fields(editor)
+	if len(parts) == 0 {
+		parts = []string{dfltEditor}
+	}
+
+	return parts
+}
+
+// editorOutput returns the file that the editor should write to. The
+// editor needs a terminal to draw on and so this is the responder's output
+// only if that is a terminal (for instance, when the prompts are written to
+// standard error); otherwise it is standard output.
+func (r R) editorOutput() *os.File {
+	if f, ok := unwrapCRLF(r.out).(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		return f
+	}
+
+	return os.Stdout
+}
+
+// runEditor runs the editor on a temporary file and returns the contents of
+// the file after the editor exits. The editor's output is written to out.
+// The terminal must not be in raw mode when this is called.
+func runEditor(out *os.File) (string, error) {
+	f, err := os.CreateTemp("", "responder-*.txt")
+	if err != nil {
+		return "", fmt.Errorf("cannot create the file to edit: %w", err)
+	}
+	fName := f.Name()
+	defer os.Remove(fName)
+
+	if err = f.Close(); err != nil {
+		return "", fmt.Errorf("cannot close the file to edit: %w", err)
+	}
+
+	parts := editorCmd()
+	cmd := exec.Command(parts[0], append(parts[1:], fName)...) //nolint: gosec
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = out
+	cmd.Stderr = os.Stderr
+
+	if err = cmd.Run(); err != nil {
+		return "", fmt.Errorf("the editor (%s) failed: %w", parts[0], err)
+	}
+
+	content, err := os.ReadFile(fName)
+	if err != nil {
+		return "", fmt.Errorf("cannot read the edited file: %w", err)
+	}
+
+	return string(content), nil
+}
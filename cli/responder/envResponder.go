@@ -0,0 +1,120 @@
+package responder
+
+import (
+	"fmt"
+	"os"
+	"unicode"
+	"unicode/utf8"
+)
+
+// EnvResponder takes its response from an environment variable rather than
+// from the user. This can be used to make an otherwise interactive program
+// scriptable, for instance when run in a CI pipeline.
+//
+// If the environment variable is set and not empty then its first rune is
+// used as the response; it is checked in exactly the same way as a rune
+// read from the terminal would be. The value of the environment variable
+// takes precedence over any default response. If the environment variable
+// is unset or empty then the default response is returned if one has been
+// set, otherwise an error is returned.
+type EnvResponder struct {
+	varName string
+	r       *R
+}
+
+// NewEnvResponder creates an EnvResponder which will take its response
+// from the named environment variable. The responses and options are
+// checked as for the New function.
+func NewEnvResponder(
+	varName string,
+	responses map[rune]string,
+	opts ...RespOptFunc,
+) (*EnvResponder, error) {
+	if varName == "" {
+		return nil, fmt.Errorf("the environment variable name must not be empty")
+	}
+
+	r, err := New(varName, responses, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &EnvResponder{
+		varName: varName,
+		r:       r,
+	}, nil
+}
+
+// NewEnvResponderOrPanic creates a new EnvResponder and panics if there are
+// any errors
+func NewEnvResponderOrPanic(
+	varName string,
+	responses map[rune]string,
+	opts ...RespOptFunc,
+) *EnvResponder {
+	er, err := NewEnvResponder(varName, responses, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return er
+}
+
+// GetResponse returns the response taken from the environment variable or
+// the default if the variable is unset or empty.
+//
+// If an error is detected the response returned will be the unicode
+// ReplacementChar.
+func (er EnvResponder) GetResponse() (rune, error) {
+	val := os.Getenv(er.varName)
+	if val == "" {
+		dflt, ok, err := er.r.Default()
+		if err != nil {
+			return unicode.ReplacementChar, err
+		}
+		if ok {
+			return dflt, nil
+		}
+
+		return unicode.ReplacementChar,
+			fmt.Errorf("the environment variable %q is not set"+
+				" and there is no default response",
+				er.varName)
+	}
+
+	input, _ := utf8.DecodeRuneInString(val)
+
+	resp, kind, err := er.r.Classify(input)
+	if err != nil {
+		return resp, fmt.Errorf("environment variable %q: %w", er.varName, err)
+	}
+
+	if kind == KindHelp {
+		return unicode.ReplacementChar,
+			fmt.Errorf("environment variable %q: help cannot be requested",
+				er.varName)
+	}
+
+	return resp, nil
+}
+
+// GetResponseOrDie calls GetResponse to get the response but if there is an
+// error it will print it and exit with status 1.
+func (er EnvResponder) GetResponseOrDie() rune {
+	resp, err := er.GetResponse()
+	if err != nil {
+		er.r.reportErrAndExit(err)
+	}
+
+	return resp
+}
+
+// GetResponseIndent returns the response taken from the environment
+// variable. The indents are ignored.
+func (er EnvResponder) GetResponseIndent(_, _ int) (rune, error) {
+	return er.GetResponse()
+}
+
+// GetResponseIndentOrDie calls GetResponseOrDie. The indents are ignored.
+func (er EnvResponder) GetResponseIndentOrDie(_, _ int) rune {
+	return er.GetResponseOrDie()
+}
@@ -0,0 +1,31 @@
+package responder
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrAborted is returned (along with the response) when the user chooses
+// the abort response (see SetAbortResponse)
+var ErrAborted = errors.New("aborted")
+
+// ErrCancelled is returned when the user cancels the prompt, for instance
+// by pressing the Escape key (see SetEscapeCancels), or when the prompt is
+// cancelled by the caller (see GetResponseWithCancel)
+var ErrCancelled = errors.New("cancelled")
+
+// ErrTimedOut is returned when no response was given before the deadline
+// (see GetResponseDeadline)
+var ErrTimedOut = errors.New("timed out")
+
+// BadResponseError is returned when the user enters a character which is
+// not a valid response. The Rune is the character that was entered. Use
+// errors.As to recover it from the error returned by GetResponse.
+type BadResponseError struct {
+	Rune rune
+}
+
+// Error returns the error message
+func (e BadResponseError) Error() string {
+	return fmt.Sprintf("bad response: %c", e.Rune)
+}
@@ -0,0 +1,117 @@
+package responder
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	escRune = '\x1b'
+
+	dfltEscapeTimeout = 50 * time.Millisecond
+)
+
+// SetEscapeCancels makes the responder treat a lone press of the Escape
+// key as a request to cancel the prompt; the response will be the unicode
+// ReplacementChar and the error will be ErrCancelled.
+//
+// Many keys (such as the arrow keys) send a sequence of characters starting
+// with an Escape character. To distinguish these from a lone Escape the
+// responder waits for a short time (50 milliseconds by default, see
+// SetEscapeTimeout) for more input to arrive. If none does then the Escape
+// is taken to be a lone keypress. Any escape sequence is discarded and
+// reported as an invalid response.
+//
+// Waiting for the rest of an escape sequence needs the input to be polled.
+// If the input cannot be polled (it is not a file, or polling for input is
+// not supported on this platform; it is supported on Unix-like systems and
+// Windows) then any Escape is taken as a lone keypress unless the rest of
+// the sequence has already been read. On such platforms pressing an arrow
+// key or a function key will cancel the prompt.
+//
+// This cannot be used with SetLineMode.
+func SetEscapeCancels() RespOptFunc {
+	return func(r *R) error {
+		r.escCancels = true
+
+		return nil
+	}
+}
+
+// SetEscapeTimeout sets the time that the responder will wait after reading
+// an Escape character for the rest of an escape sequence to arrive. It is
+// only used if SetEscapeCancels has been given. The timeout must be greater
+// than zero.
+func SetEscapeTimeout(d time.Duration) RespOptFunc {
+	return func(r *R) error {
+		if d <= 0 {
+			return fmt.Errorf(
+				"SetEscapeTimeout: the timeout (%s) must be greater than 0",
+				d)
+		}
+
+		r.escTimeout = d
+
+		return nil
+	}
+}
+
+// checkEscape is called after an Escape character has been read. It
+// returns ErrCancelled if the Escape was a lone keypress. Otherwise it
+// discards the rest of the escape sequence and returns an error reporting
+// the bad response.
+func (r R) checkEscape() error {
+	if r.rdr.Buffered() == 0 {
+		ready, err := waitForInput(r.fd, r.escTimeout)
+		if err != nil || !ready {
+			return ErrCancelled
+		}
+	}
+
+	r.discardEscSeq()
+
+	return fmt.Errorf("bad response: an escape sequence")
+}
+
+// discardEscSeq reads and discards the remainder of an escape sequence. The
+// leading Escape character has already been read.
+func (r R) discardEscSeq() {
+	_ = r.readEscSeq()
+}
+
+// readEscSeq reads the remainder of an escape sequence and returns the
+// whole sequence including the leading Escape character, which has already
+// been read.
+func (r R) readEscSeq() string {
+	seq := []byte{escRune}
+
+	b, err := r.rdr.ReadByte()
+	if err != nil {
+		return string(seq)
+	}
+	seq = append(seq, b)
+
+	switch b {
+	case '[':
+		// a CSI sequence is ended by a byte in the range 0x40-0x7e. The
+		// Linux console sends F1 to F5 as ESC [ [ followed by a letter so
+		// a second '[' is taken as part of the sequence
+		for {
+			b, err = r.rdr.ReadByte()
+			if err != nil {
+				return string(seq)
+			}
+			seq = append(seq, b)
+			if b >= 0x40 && b <= 0x7e && !(b == '[' && len(seq) == 3) {
+				return string(seq)
+			}
+		}
+	case 'O':
+		// an SS3 sequence has a single following byte
+		if b, err = r.rdr.ReadByte(); err == nil {
+			seq = append(seq, b)
+		}
+	}
+
+	return string(seq)
+}
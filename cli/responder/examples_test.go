@@ -2,6 +2,9 @@ package responder_test
 
 import (
 	"fmt"
+	"os"
+	"sort"
+	"strings"
 
 	"github.com/nickwells/cli.mod/cli/responder"
 )
@@ -62,3 +65,489 @@ func ExampleR_PrintPrompt_withDefault() {
 	// Output:
 	// Delete File? ([y]/n/?):
 }
+
+// This example shows how a response can be taken from an environment
+// variable rather than from the user
+func ExampleEnvResponder() {
+	r := responder.NewEnvResponderOrPanic(
+		"DELETE_FILE",
+		map[rune]string{
+			'y': "delete the file",
+			'n': "leave the file alone",
+		},
+		responder.SetDefault('n'),
+	)
+
+	os.Setenv("DELETE_FILE", "yes")
+	fmt.Println(string(r.GetResponseOrDie()))
+
+	os.Unsetenv("DELETE_FILE")
+	fmt.Println(string(r.GetResponseOrDie()))
+	// Output:
+	// y
+	// n
+}
+
+// This example shows how a FuncResponder can be used to give a different
+// response on each call
+func ExampleFuncResponder() {
+	responses := []rune{'y', 'y', 'q'}
+	i := 0
+
+	var r responder.Responder = responder.FuncResponder(
+		func() (rune, error) {
+			resp := responses[i]
+			i++
+			return resp, nil
+		})
+
+	for {
+		resp := r.GetResponseOrDie()
+		fmt.Println(string(resp))
+		if resp == 'q' {
+			break
+		}
+	}
+	// Output:
+	// y
+	// y
+	// q
+}
+
+// This example shows how a FileAnswerer can be used to answer prompts
+// without asking the user
+func ExampleFileAnswerer() {
+	fa := responder.NewFileAnswerer(map[string]rune{"delete": 'y'})
+
+	resps := map[rune]string{
+		'y': "yes",
+		'n': "no",
+	}
+	del := responder.NewOrPanic("Delete File", resps,
+		responder.SetID("delete"))
+	overwrite := responder.NewOrPanic("Overwrite File", resps,
+		responder.SetID("overwrite"), responder.SetDefault('n'))
+
+	fmt.Println(string(fa.For(del).GetResponseOrDie()))
+	fmt.Println(string(fa.For(overwrite).GetResponseOrDie()))
+	// Output:
+	// y
+	// n
+}
+
+// This example shows how the responses can be retrieved in order
+func ExampleR_SortedResponses() {
+	r := responder.NewOrPanic(
+		"Delete File",
+		map[rune]string{
+			'y': "delete the file",
+			'n': "leave the file alone",
+		},
+	)
+	for _, resp := range r.SortedResponses() {
+		fmt.Printf("%c: %s\n", resp.Rune, resp.Desc)
+	}
+	// Output:
+	// n: leave the file alone
+	// y: delete the file
+}
+
+// This example shows the prompt printed when the SetSmartSuffix option is
+// used and the prompt already ends with a question mark
+func ExampleSetSmartSuffix() {
+	r := responder.NewOrPanic(
+		"Delete the file?",
+		map[rune]string{
+			'y': "delete the file",
+			'n': "leave the file alone",
+		},
+		responder.SetSmartSuffix(),
+	)
+	r.PrintPrompt()
+	// Output:
+	// Delete the file? (n/y/?):
+}
+
+// This example shows how the width of the prompt can be found
+func ExampleR_PromptWidth() {
+	r := responder.NewOrPanic(
+		"Delete File",
+		map[rune]string{
+			'y': "delete the file",
+			'n': "leave the file alone",
+		},
+	)
+	fmt.Printf("%q: %d\n", r.PromptString(), r.PromptWidth())
+	// Output:
+	// "Delete File? (n/y/?): ": 22
+}
+
+// This example shows the prompt printed when the SetShowDefaultKey option is
+// used
+func ExampleSetShowDefaultKey() {
+	r := responder.NewOrPanic(
+		"Delete File",
+		map[rune]string{
+			'y': "delete the file",
+			'n': "leave the file alone",
+		},
+		responder.SetDefault('y'),
+		responder.SetShowDefaultKey(),
+	)
+	r.PrintPrompt()
+	// Output:
+	// Delete File? (Enter=y/n/?):
+}
+
+// This example shows how the prompt can be generated each time it is
+// printed
+func ExampleSetPromptFunc() {
+	fileNum := 0
+	r := responder.NewOrPanic(
+		"",
+		map[rune]string{
+			'y': "delete the file",
+			'n': "leave the file alone",
+		},
+		responder.SetPromptFunc(func() string {
+			return fmt.Sprintf("File %d of 2: delete", fileNum)
+		}),
+	)
+	for fileNum = 1; fileNum <= 2; fileNum++ {
+		fmt.Printf("%q\n", r.PromptString())
+	}
+	// Output:
+	// "File 1 of 2: delete? (n/y/?): "
+	// "File 2 of 2: delete? (n/y/?): "
+}
+
+// This example shows the prompt printed when the SetAutoHideHelp option is
+// used and none of the responses have a description
+func ExampleSetAutoHideHelp() {
+	r := responder.NewOrPanic(
+		"Continue",
+		map[rune]string{
+			'y': "",
+			'n': "",
+		},
+		responder.SetAutoHideHelp(),
+	)
+	r.PrintPrompt()
+	// Output:
+	// Continue? (n/y):
+}
+
+// This example shows how an OptionalResponder returns the fallback response
+// when it is not reading from a terminal
+func ExampleOptionalResponder() {
+	r := responder.NewOptionalOrPanic(
+		"Delete File",
+		map[rune]string{
+			'y': "delete the file",
+			'n': "leave the file alone",
+		},
+		'n',
+		responder.SetInput(strings.NewReader("y")),
+	)
+	fmt.Println(string(r.GetResponseOrDie()))
+	// Output:
+	// n
+}
+
+// This example shows how a responder can be created from a list of labels
+func ExampleNewFromLabels() {
+	r, err := responder.NewFromLabels("Action",
+		[]string{"save", "skip", "quit"})
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	for _, resp := range r.SortedResponses() {
+		fmt.Printf("%c: %s\n", resp.Rune, resp.Desc)
+	}
+	// Output:
+	// k: skip
+	// q: quit
+	// s: save
+}
+
+// This example shows the keys that can be pressed and the responses they
+// produce
+func ExampleR_KeyMap() {
+	r := responder.NewOrPanic(
+		"Delete File",
+		map[rune]string{
+			'y': "delete the file",
+			'n': "leave the file alone",
+		},
+		responder.SetDefault('n'),
+	)
+
+	km := r.KeyMap()
+	keys := make([]rune, 0, len(km))
+	for k := range km {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+
+	for _, k := range keys {
+		fmt.Printf("%q -> %q\n", k, km[k])
+	}
+	// Output:
+	// '\t' -> 'n'
+	// '\n' -> 'n'
+	// '\r' -> 'n'
+	// ' ' -> 'n'
+	// '?' -> '?'
+	// 'N' -> 'n'
+	// 'Y' -> 'y'
+	// 'n' -> 'n'
+	// 'y' -> 'y'
+}
+
+// This example shows the prompt printed when a prompt template is used
+func ExampleSetPromptTemplate() {
+	r := responder.NewOrPanic(
+		"Delete File",
+		map[rune]string{
+			'y': "delete the file",
+			'n': "leave the file alone",
+		},
+		responder.SetDefault('n'),
+		responder.SetPromptTemplate("{prompt} [{responses}] » "),
+	)
+	fmt.Printf("%q\n", r.PromptString())
+	// Output:
+	// "Delete File [[n]/y/?] » "
+}
+
+// This example shows the prompt printed when the SetNoParens option is used
+func ExampleSetNoParens() {
+	r := responder.NewOrPanic(
+		"Delete File",
+		map[rune]string{
+			'y': "delete the file",
+			'n': "leave the file alone",
+		},
+		responder.SetNoParens(),
+	)
+	r.PrintPrompt()
+	// Output:
+	// Delete File? n/y/?:
+}
+
+// This example shows how a ReplayResponder can answer each prompt from a
+// map keyed by the prompt text, giving the default for any other prompt
+func ExampleReplayResponder() {
+	files := []string{"a.txt", "b.txt", "c.txt"}
+	i := 0
+
+	r := responder.NewOrPanic("", map[rune]string{
+		'y': "yes",
+		'n': "no",
+	},
+		responder.SetPromptFunc(func() string {
+			return "Delete " + files[i]
+		}),
+		responder.SetDefault('n'))
+
+	rr, err := responder.NewReplayResponder(r, map[string]rune{
+		"Delete a.txt": 'y',
+		"Delete c.txt": 'Y',
+	})
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+
+	for i = range files {
+		fmt.Println(files[i], string(rr.GetResponseOrDie()))
+	}
+	// Output:
+	// a.txt y
+	// b.txt n
+	// c.txt y
+}
+
+// This example shows the help message printed when the SetDefaultHelpMarker
+// option is used to mark the default response
+func ExampleSetDefaultHelpMarker() {
+	r := responder.NewOrPanic(
+		"Delete File",
+		map[rune]string{
+			'y': "delete the file",
+			'n': "leave the file alone",
+		},
+		responder.SetDefault('n'),
+		responder.SetDefaultHelpMarker("* "),
+	)
+	r.PrintHelp()
+	// Output:
+	//
+	// Enter one of:
+	//     * n  leave the file alone
+	//       y  delete the file
+	//       ?  to show this message
+	//
+	// to select the default either enter the character or whitespace (a space, tab or
+	// return character)
+}
+
+// This example shows how the help message can be indented so that it lines
+// up with other output, without the leading blank line
+func ExampleR_PrintHelpIndentNoLead() {
+	r := responder.NewOrPanic(
+		"Delete File",
+		map[rune]string{
+			'y': "delete the file",
+			'n': "leave the file alone",
+		},
+		responder.SetOutput(os.Stdout),
+	)
+	fmt.Println("Deleting files:")
+	r.PrintHelpIndentNoLead(2)
+	// Output:
+	// Deleting files:
+	//   Enter one of:
+	//       n  leave the file alone
+	//       y  delete the file
+	//       ?  to show this message
+	//
+	//   to select the default either enter the character or whitespace (a space, tab
+	//   or return character)
+}
+
+// This example shows how the descriptions of the responses can be supplied
+// by a function, here translating them into French. Responses for which the
+// function gives an empty string keep their own description
+func ExampleSetDescriptionFunc() {
+	french := map[rune]string{
+		'y': "oui",
+		'n': "non",
+	}
+
+	r := responder.NewOrPanic(
+		"Delete File",
+		map[rune]string{
+			'y': "yes",
+			'n': "no",
+			'q': "quit",
+		},
+		responder.SetDescriptionFunc(func(c rune) string { return french[c] }),
+		responder.SetOutput(os.Stdout),
+	)
+	for _, resp := range r.SortedResponses() {
+		fmt.Printf("%c: %s\n", resp.Rune, resp.Desc)
+	}
+	// Output:
+	// n: non
+	// q: quit
+	// y: oui
+}
+
+// This example shows how a responder can be created from the tagged fields
+// of a struct and the error reported when a tag is not valid
+func ExampleNewFromStruct() {
+	type choices struct {
+		Yes  bool `resp:"y" desc:"delete the file"`
+		No   bool `resp:"n" desc:"keep the file"`
+		Quit bool `resp:"q" desc:"stop asking"`
+		Note string
+	}
+
+	r, err := responder.NewFromStruct("Delete File", choices{},
+		responder.SetOutput(os.Stdout))
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	for _, resp := range r.SortedResponses() {
+		fmt.Printf("%c: %s\n", resp.Rune, resp.Desc)
+	}
+
+	_, err = responder.NewFromStruct("Delete File", struct {
+		Yes bool `resp:"Y" desc:"delete the file"`
+		No  bool `resp:"n" desc:"keep the file"`
+	}{})
+	fmt.Println("Error:", err)
+	// Output:
+	// n: keep the file
+	// q: stop asking
+	// y: delete the file
+	// Error: field Yes: only lowercase responses are allowed - 'Y' is uppercase
+}
+
+// This example shows how a responder can be created from lines of text and
+// the error reported when a response is given twice
+func ExampleNewFromReader() {
+	cfg := `# the choices offered when a file exists
+y: delete the file
+n: keep the file
+
+q: stop asking
+`
+
+	r, err := responder.NewFromReader("Delete File", strings.NewReader(cfg),
+		responder.SetOutput(os.Stdout))
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	for _, resp := range r.SortedResponses() {
+		fmt.Printf("%c: %s\n", resp.Rune, resp.Desc)
+	}
+
+	_, err = responder.NewFromReader("Delete File",
+		strings.NewReader("y: delete the file\ny: keep the file\n"))
+	fmt.Println("Error:", err)
+	// Output:
+	// n: keep the file
+	// q: stop asking
+	// y: delete the file
+	// Error: line 2: the response 'y' is also given on line 1
+}
+
+// This example shows how the details of the prompt can be retrieved as
+// structured data
+func ExampleR_Describe() {
+	r := responder.NewOrPanic(
+		"Delete File",
+		map[rune]string{
+			'y': "delete the file",
+			'n': "leave the file alone",
+		},
+		responder.SetDefault('n'),
+	)
+
+	pi := r.Describe()
+	fmt.Println("prompt:", pi.Prompt)
+	for _, resp := range pi.Responses {
+		fmt.Printf("    %c: %s\n", resp.Rune, resp.Desc)
+	}
+	if pi.HasDefault {
+		fmt.Printf("default: %c\n", pi.Default)
+	}
+	fmt.Println("help enabled:", pi.HelpEnabled)
+	// Output:
+	// prompt: Delete File
+	//     n: leave the file alone
+	//     y: delete the file
+	// default: n
+	// help enabled: true
+}
+
+// This example shows the prompt printed when the SetNoTrailingSpace option
+// is used
+func ExampleSetNoTrailingSpace() {
+	r := responder.NewOrPanic(
+		"Delete File",
+		map[rune]string{
+			'y': "delete the file",
+			'n': "leave the file alone",
+		},
+		responder.SetNoTrailingSpace(),
+	)
+	fmt.Printf("%q\n", r.PromptString())
+	// Output:
+	// "Delete File? (n/y/?):"
+}
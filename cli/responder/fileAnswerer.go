@@ -0,0 +1,130 @@
+package responder
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"unicode"
+	"unicode/utf8"
+)
+
+// FileAnswerer holds a collection of answers keyed by the ID of the
+// responder (see SetID). It can be used to answer many prompts
+// non-interactively, for instance from an answers file.
+type FileAnswerer struct {
+	answers map[string]rune
+}
+
+// NewFileAnswerer creates a FileAnswerer from the map of answers keyed by
+// responder ID.
+func NewFileAnswerer(answers map[string]rune) *FileAnswerer {
+	fa := &FileAnswerer{
+		answers: make(map[string]rune, len(answers)),
+	}
+	for k, v := range answers {
+		fa.answers[k] = v
+	}
+
+	return fa
+}
+
+// LoadFileAnswerer reads the named file and creates a FileAnswerer from it.
+// The file should contain a JSON object whose keys are the responder IDs
+// and whose values are strings of exactly one character, the answer. For
+// instance:
+//
+//	{"delete": "y", "overwrite": "n"}
+func LoadFileAnswerer(fileName string) (*FileAnswerer, error) {
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read the answers file: %w", err)
+	}
+
+	var vals map[string]string
+	if err = json.Unmarshal(content, &vals); err != nil {
+		return nil,
+			fmt.Errorf("cannot parse the answers file %q: %w", fileName, err)
+	}
+
+	answers := make(map[string]rune, len(vals))
+	for k, v := range vals {
+		if utf8.RuneCountInString(v) != 1 {
+			return nil,
+				fmt.Errorf("the answers file %q: the answer for %q (%q)"+
+					" must be a single character",
+					fileName, k, v)
+		}
+		answers[k], _ = utf8.DecodeRuneInString(v)
+	}
+
+	return &FileAnswerer{answers: answers}, nil
+}
+
+// For returns a Responder which will answer the prompts of the given
+// responder from the FileAnswerer's answers, using the responder's ID to
+// find the answer. The answer is checked in the same way as a rune read
+// from the terminal would be so that stale answers will be detected. If
+// there is no answer for the responder's ID then the default response is
+// returned if one has been set, otherwise an error is returned.
+func (fa *FileAnswerer) For(r *R) Responder {
+	return fileAnswer{fa: fa, r: r}
+}
+
+// fileAnswer is the Responder returned by FileAnswerer.For
+type fileAnswer struct {
+	fa *FileAnswerer
+	r  *R
+}
+
+// GetResponse returns the answer for the responder
+func (a fileAnswer) GetResponse() (rune, error) {
+	input, ok := a.fa.answers[a.r.id]
+	if !ok {
+		dflt, ok, err := a.r.Default()
+		if err != nil {
+			return unicode.ReplacementChar, err
+		}
+		if ok {
+			return dflt, nil
+		}
+
+		return unicode.ReplacementChar,
+			fmt.Errorf("there is no answer for %q"+
+				" and there is no default response",
+				a.r.id)
+	}
+
+	resp, kind, err := a.r.Classify(input)
+	if err != nil {
+		return resp, fmt.Errorf("the answer for %q: %w", a.r.id, err)
+	}
+
+	if kind == KindHelp {
+		return unicode.ReplacementChar,
+			fmt.Errorf("the answer for %q: help cannot be requested", a.r.id)
+	}
+
+	return resp, nil
+}
+
+// GetResponseOrDie calls GetResponse to get the response but if there is an
+// error it will print it and exit with status 1.
+func (a fileAnswer) GetResponseOrDie() rune {
+	resp, err := a.GetResponse()
+	if err != nil {
+		a.r.reportErrAndExit(err)
+	}
+
+	return resp
+}
+
+// GetResponseIndent returns the answer for the responder. The indents are
+// ignored.
+func (a fileAnswer) GetResponseIndent(_, _ int) (rune, error) {
+	return a.GetResponse()
+}
+
+// GetResponseIndentOrDie calls GetResponseOrDie. The indents are ignored.
+func (a fileAnswer) GetResponseIndentOrDie(_, _ int) rune {
+	return a.GetResponseOrDie()
+}
@@ -0,0 +1,199 @@
+package responder
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/nickwells/twrap.mod/twrap"
+)
+
+// GetFixedLength prints the prompt and reads a code of exactly n
+// characters, for instance a two-letter country code, without the user
+// needing to press Enter. The characters are echoed as they are typed and
+// the user may use backspace to correct them. The code must be one of the
+// keys of the allowed map; the values are descriptions of the codes which
+// are shown in the help message. Unless SetNoFold is given the code is
+// matched ignoring case. If the code is not allowed an error is printed and
+// the user is asked again, up to the limit set by SetMaxReprompts.
+//
+// Entering the help rune ('?') as the first character will show the
+// allowed codes, unless SetNoHelp is given. The allowed codes must all be n
+// characters long and must not contain whitespace or the help rune.
+//
+// The options are as for New but any options which refer to the valid
+// responses (such as SetDefault) cannot be used.
+func GetFixedLength(
+	prompt string,
+	n int,
+	allowed map[string]string,
+	opts ...RespOptFunc,
+) (string, error) {
+	if err := checkCodes(n, allowed); err != nil {
+		return "", err
+	}
+
+	r := newR(prompt)
+	for _, o := range opts {
+		if err := o(r); err != nil {
+			return "", err
+		}
+	}
+
+	return r.getCode(n, allowed)
+}
+
+// checkCodes checks that the allowed codes are all of length n and do not
+// contain any forbidden characters
+func checkCodes(n int, allowed map[string]string) error {
+	if n <= 0 {
+		return fmt.Errorf("the code length (%d) must be greater than 0", n)
+	}
+	if len(allowed) == 0 {
+		return fmt.Errorf("there must be at least one allowed code")
+	}
+
+	for code, desc := range allowed {
+		if l := utf8.RuneCountInString(code); l != n {
+			return fmt.Errorf("the code %q has %d characters, it should have %d",
+				code, l, n)
+		}
+		for _, c := range code {
+			if unicode.IsSpace(c) || c == helpRune || !unicode.IsPrint(c) {
+				return fmt.Errorf("the code %q contains a bad character: %q",
+					code, c)
+			}
+		}
+		for _, c := range desc {
+			if unicode.IsControl(c) {
+				return fmt.Errorf(
+					"the description of %q contains a control character (%U)"+
+						" - only printable characters and spaces are allowed",
+					code, c)
+			}
+		}
+	}
+
+	return nil
+}
+
+// sortedCodes returns the allowed codes in sorted order
+func sortedCodes(allowed map[string]string) []string {
+	codes := make([]string, 0, len(allowed))
+	for code := range allowed {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	return codes
+}
+
+// getCode prompts for and reads a code of n characters, reprompting if the
+// code is not allowed
+func (r R) getCode(n int, allowed map[string]string) (string, error) {
+	codes := sortedCodes(allowed)
+
+	respList := strings.Join(codes, "/")
+	if !r.noHelp {
+		respList += "/" + string(helpRune)
+	}
+
+	prefix := strings.Repeat(" ", r.indentFirst)
+	secondPrefix := strings.Repeat(" ", r.indent)
+
+	for i := 1; ; i++ {
+		fmt.Fprint(r.out, prefix+r.promptText()+"("+respList+"): ")
+		prefix = secondPrefix
+
+		code, isHelp, err := r.readCode(n)
+		if err != nil {
+			r.state.lastErr = err
+			r.record("error", err.Error())
+			return "", err
+		}
+		if isHelp {
+			r.printCodeHelp(codes, allowed)
+			i--
+			continue
+		}
+
+		if match, ok := r.matchCode(code, codes); ok {
+			r.record("answer", match)
+			return match, nil
+		}
+
+		err = fmt.Errorf("bad response: %s", code)
+		r.state.lastErr = err
+		r.record("error", err.Error())
+
+		if r.limitPrompts && i > r.maxReprompts {
+			return "", err
+		}
+
+		r.printErr(err, r.indent)
+	}
+}
+
+// readCode reads n characters, echoing them as they are typed. It returns
+// true if the first character is the help rune.
+func (r R) readCode(n int) (string, bool, error) {
+	code := make([]rune, 0, n)
+
+	for len(code) < n {
+		c, err := r.getRune()
+		if err != nil {
+			return string(code), false, err
+		}
+
+		if isBackspace(c) {
+			if len(code) > 0 {
+				code = code[:len(code)-1]
+				fmt.Fprint(r.out, "\b \b")
+			}
+			continue
+		}
+
+		if len(code) == 0 && c == helpRune && !r.noHelp {
+			return "", true, nil
+		}
+
+		code = append(code, c)
+		fmt.Fprint(r.out, string(c))
+	}
+
+	return string(code), false, nil
+}
+
+// matchCode returns the allowed code matching the given code, ignoring
+// case unless SetNoFold has been given
+func (r R) matchCode(code string, codes []string) (string, bool) {
+	for _, c := range codes {
+		if c == code || (!r.noFold && strings.EqualFold(c, code)) {
+			return c, true
+		}
+	}
+
+	return "", false
+}
+
+// printCodeHelp prints the help message showing the allowed codes
+func (r R) printCodeHelp(codes []string, allowed map[string]string) {
+	twc := twrap.NewTWConfOrPanic(twrap.SetWriter(r.out))
+
+	twc.Println() //nolint: errcheck
+	twc.Wrap("Enter one of:", r.indent)
+
+	width := 0
+	for _, code := range codes {
+		if w := utf8.RuneCountInString(code); w > width {
+			width = w
+		}
+	}
+
+	for _, code := range codes {
+		twc.WrapPrefixed(helpKeyPrefix(code, width), allowed[code], r.indent+4)
+	}
+	twc.Println() //nolint: errcheck
+}
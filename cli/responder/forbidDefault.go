@@ -0,0 +1,63 @@
+package responder
+
+import "fmt"
+
+// SetForbidDefault gives responses which must never be the default. This
+// can be used to enforce a policy that a dangerous response, such as one
+// which deletes a file, is never chosen just by pressing Enter. The
+// responses need not be valid responses so that the same set can be
+// given to every responder. This option may be given more than once and
+// the responses are added to those already given.
+//
+// It is an error if a default given by SetDefault, SetInteractiveDefault or
+// SetNonInteractiveDefault is one of these responses. A default function
+// (see SetDefaultFunc) can only be checked when it is called and so an
+// error will be returned then. If SetTabCyclesDefault has been given, Tab
+// will skip over these responses.
+func SetForbidDefault(runes ...rune) RespOptFunc {
+	return func(r *R) error {
+		if len(runes) == 0 {
+			return fmt.Errorf(
+				"SetForbidDefault: at least one response must be given")
+		}
+
+		if r.forbiddenDflts == nil {
+			r.forbiddenDflts = make(map[rune]bool, len(runes))
+		}
+		for _, c := range runes {
+			r.forbiddenDflts[c] = true
+		}
+
+		return nil
+	}
+}
+
+// checkForbiddenDefaults checks that none of the defaults is a forbidden
+// response
+func (r R) checkForbiddenDefaults() error {
+	for _, d := range []struct {
+		opt     string
+		has     bool
+		setting rune
+	}{
+		{"SetDefault", r.hasDflt, r.dflt},
+		{"SetInteractiveDefault", r.hasInterDflt, r.interDflt},
+		{"SetNonInteractiveDefault", r.hasNonInterDflt, r.nonInterDflt},
+	} {
+		if d.has && r.forbiddenDflts[d.setting] {
+			return fmt.Errorf("%s: %w", d.opt, forbiddenDefaultErr(d.setting))
+		}
+	}
+
+	return nil
+}
+
+// forbiddenDefaultErr returns the error reported when the default is one
+// of the forbidden responses
+func forbiddenDefaultErr(d rune) error {
+	return fmt.Errorf(
+		"the default response (%c) is forbidden"+
+			" - it has been marked as a dangerous response"+
+			" which must never be the default (see SetForbidDefault)",
+		d)
+}
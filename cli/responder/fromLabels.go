@@ -0,0 +1,61 @@
+package responder
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// NewFromLabels creates a responder from a list of labels. Each label is
+// given a response which is the first letter in the label (as lowercase)
+// that is not already in use for an earlier label; the label is used as the
+// description of the response. For instance, the labels "save", "skip" and
+// "quit" would be given the responses 's', 'k' and 'q'.
+//
+// If a label has no letter which is not already in use an error is
+// returned listing the labels which could not be given a response. The
+// responses and options are then checked as for the New function.
+func NewFromLabels(
+	prompt string,
+	labels []string,
+	opts ...RespOptFunc,
+) (*R, error) {
+	responses := make(map[rune]string, len(labels))
+	seen := make(map[string]bool, len(labels))
+	unassigned := []string{}
+
+	for _, l := range labels {
+		if seen[l] {
+			return nil, fmt.Errorf("the label %q is given more than once", l)
+		}
+		seen[l] = true
+
+		assigned := false
+		for _, c := range l {
+			if !unicode.IsLetter(c) {
+				continue
+			}
+			c = unicode.ToLower(c)
+			if _, inUse := responses[c]; inUse {
+				continue
+			}
+
+			responses[c] = l
+			assigned = true
+
+			break
+		}
+
+		if !assigned {
+			unassigned = append(unassigned, fmt.Sprintf("%q", l))
+		}
+	}
+
+	if len(unassigned) > 0 {
+		return nil,
+			fmt.Errorf("no distinct letter could be found for: %s",
+				strings.Join(unassigned, ", "))
+	}
+
+	return New(prompt, responses, opts...)
+}
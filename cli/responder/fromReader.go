@@ -0,0 +1,84 @@
+package responder
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// NewFromReader creates a responder from the responses read from the
+// io.Reader. This allows the choices offered to be given in a
+// configuration file rather than being fixed in the program. Each line
+// gives a response and its description in the form:
+//
+//	y: delete the file
+//
+// The response must be exactly one character and it is separated from the
+// description by a colon; any whitespace around the response or the
+// description is removed. Blank lines and lines starting with '#' are
+// ignored.
+//
+// Each response and description is checked as for the New function and
+// any error gives the number of the bad line. A response given on more
+// than one line is also reported as an error. The responses and options
+// are then checked as for the New function.
+func NewFromReader(
+	prompt string,
+	rdr io.Reader,
+	opts ...RespOptFunc,
+) (*R, error) {
+	responses := map[rune]string{}
+	lines := map[rune]int{}
+
+	scanner := bufio.NewScanner(rdr)
+	lineNum := 0
+
+	for scanner.Scan() {
+		lineNum++
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		resp, desc, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil,
+				fmt.Errorf("line %d: %q is not of the form"+
+					" 'response: description'",
+					lineNum, line)
+		}
+
+		resp = strings.TrimSpace(resp)
+		if utf8.RuneCountInString(resp) != 1 {
+			return nil,
+				fmt.Errorf("line %d: the response (%q)"+
+					" must be a single character",
+					lineNum, resp)
+		}
+		c, _ := utf8.DecodeRuneInString(resp)
+
+		if other, dup := lines[c]; dup {
+			return nil,
+				fmt.Errorf("line %d: the response '%c'"+
+					" is also given on line %d",
+					lineNum, c, other)
+		}
+
+		desc = strings.TrimSpace(desc)
+		if err := checkResponse(c, desc, helpRune); err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNum, err)
+		}
+
+		responses[c] = desc
+		lines[c] = lineNum
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read the responses: %w", err)
+	}
+
+	return New(prompt, responses, opts...)
+}
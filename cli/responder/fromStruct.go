@@ -0,0 +1,85 @@
+package responder
+
+import (
+	"fmt"
+	"reflect"
+	"unicode/utf8"
+)
+
+const (
+	respTag = "resp"
+	descTag = "desc"
+)
+
+// NewFromStruct creates a responder from the tags on the fields of a
+// struct. The value must be a struct or a pointer to a struct. Each field
+// giving a response must have a tag of the form:
+//
+//	resp:"y" desc:"yes, delete the file"
+//
+// The resp tag must be exactly one character, the response. The desc tag
+// is the description of the response; it is optional and, if it is
+// missing, the description is empty. Fields without a resp tag are
+// ignored, as are the types and values of the fields. For instance:
+//
+//	type choices struct {
+//		Yes  bool `resp:"y" desc:"delete the file"`
+//		No   bool `resp:"n" desc:"keep the file"`
+//		Quit bool `resp:"q" desc:"stop asking"`
+//	}
+//
+// Each response and description is checked as for the New function and
+// any error names the field with the bad tag. A response given by more
+// than one field is also reported as an error. The responses and options
+// are then checked as for the New function.
+func NewFromStruct(
+	prompt string,
+	v any,
+	opts ...RespOptFunc,
+) (*R, error) {
+	t := reflect.TypeOf(v)
+	if t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("the value (a %T) must be a struct"+
+			" or a pointer to a struct", v)
+	}
+
+	responses := make(map[rune]string, t.NumField())
+	fields := make(map[rune]string, t.NumField())
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		tag, ok := f.Tag.Lookup(respTag)
+		if !ok {
+			continue
+		}
+
+		if utf8.RuneCountInString(tag) != 1 {
+			return nil,
+				fmt.Errorf("field %s: the %s tag (%q)"+
+					" must be a single character",
+					f.Name, respTag, tag)
+		}
+		c, _ := utf8.DecodeRuneInString(tag)
+
+		if other, dup := fields[c]; dup {
+			return nil,
+				fmt.Errorf("field %s: the response '%c'"+
+					" is also given by field %s",
+					f.Name, c, other)
+		}
+
+		desc := f.Tag.Get(descTag)
+		if err := checkResponse(c, desc, helpRune); err != nil {
+			return nil, fmt.Errorf("field %s: %w", f.Name, err)
+		}
+
+		responses[c] = desc
+		fields[c] = f.Name
+	}
+
+	return New(prompt, responses, opts...)
+}
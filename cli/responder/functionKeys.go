@@ -0,0 +1,131 @@
+package responder
+
+import (
+	"fmt"
+	"unicode"
+)
+
+const maxFunctionKey = 12
+
+// dfltFunctionKeySeqs maps the escape sequences sent by common terminals
+// (xterm and its derivatives, rxvt and the Linux console) for the function
+// keys to the number of the key
+var dfltFunctionKeySeqs = map[string]int{
+	"\x1bOP": 1, "\x1bOQ": 2, "\x1bOR": 3, "\x1bOS": 4,
+	"\x1b[11~": 1, "\x1b[12~": 2, "\x1b[13~": 3, "\x1b[14~": 4,
+	"\x1b[[A": 1, "\x1b[[B": 2, "\x1b[[C": 3, "\x1b[[D": 4, "\x1b[[E": 5,
+	"\x1b[15~": 5, "\x1b[17~": 6, "\x1b[18~": 7, "\x1b[19~": 8,
+	"\x1b[20~": 9, "\x1b[21~": 10, "\x1b[23~": 11, "\x1b[24~": 12,
+}
+
+// SetFunctionKey makes pressing the function key Fn give the response c.
+// The key number must be from 1 to 12 and the response must be in the list
+// of valid responses. The escape sequences sent by the function keys of
+// common terminals are recognised; if your terminal sends something
+// different then use SetFunctionKeySequence to give the sequence.
+//
+// When function keys have been set any other escape sequence is reported
+// as an invalid response.
+func SetFunctionKey(n int, c rune) RespOptFunc {
+	return func(r *R) error {
+		if err := checkFunctionKey(n); err != nil {
+			return fmt.Errorf("SetFunctionKey: %w", err)
+		}
+		if _, ok := r.validResps[c]; !ok {
+			return fmt.Errorf(
+				"SetFunctionKey: the response (%c) is not"+
+					" in the list of valid responses",
+				c)
+		}
+
+		if r.fnKeys == nil {
+			r.fnKeys = make(map[int]rune)
+		}
+		r.fnKeys[n] = c
+
+		return nil
+	}
+}
+
+// SetFunctionKeySequence sets the escape sequence sent by the function key
+// Fn, replacing the sequences that are recognised by default. The key
+// number must be from 1 to 12 and the sequence must start with an Escape
+// character.
+func SetFunctionKeySequence(n int, seq string) RespOptFunc {
+	return func(r *R) error {
+		if err := checkFunctionKey(n); err != nil {
+			return fmt.Errorf("SetFunctionKeySequence: %w", err)
+		}
+		if len(seq) < 2 || seq[0] != escRune {
+			return fmt.Errorf(
+				"SetFunctionKeySequence: the sequence (%q) must be"+
+					" an Escape character followed by at least one character",
+				seq)
+		}
+
+		if r.fnKeySeqs == nil {
+			r.fnKeySeqs = make(map[int]string)
+		}
+		r.fnKeySeqs[n] = seq
+
+		return nil
+	}
+}
+
+// checkFunctionKey checks that the function key number is in range
+func checkFunctionKey(n int) error {
+	if n < 1 || n > maxFunctionKey {
+		return fmt.Errorf("the function key (F%d) must be from F1 to F%d",
+			n, maxFunctionKey)
+	}
+
+	return nil
+}
+
+// functionKey returns the number of the function key which sends the
+// escape sequence and true, or false if the sequence is not recognised
+func (r R) functionKey(seq string) (int, bool) {
+	for n, s := range r.fnKeySeqs {
+		if s == seq {
+			return n, true
+		}
+	}
+
+	n, ok := dfltFunctionKeySeqs[seq]
+	if !ok {
+		return 0, false
+	}
+	if _, overridden := r.fnKeySeqs[n]; overridden {
+		return 0, false
+	}
+
+	return n, true
+}
+
+// functionKeyResp is called after an Escape character has been read and
+// function keys have been set. If the Escape starts the sequence for a
+// function key which has been given a response then that response is
+// returned. A lone Escape is returned as it is, unless SetEscapeCancels has
+// been given in which case ErrCancelled is returned. Any other escape
+// sequence is reported as a bad response.
+func (r R) functionKeyResp() (rune, error) {
+	if r.rdr.Buffered() == 0 {
+		ready, err := waitForInput(r.fd, r.escTimeout)
+		if err != nil || !ready {
+			if r.escCancels {
+				return unicode.ReplacementChar, ErrCancelled
+			}
+			return escRune, nil
+		}
+	}
+
+	seq := r.readEscSeq()
+	if n, ok := r.functionKey(seq); ok {
+		if c, ok := r.fnKeys[n]; ok {
+			return c, nil
+		}
+	}
+
+	return unicode.ReplacementChar,
+		fmt.Errorf("bad response: an escape sequence")
+}
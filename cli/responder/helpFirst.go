@@ -0,0 +1,41 @@
+package responder
+
+import (
+	"fmt"
+	"unicode"
+)
+
+// SetRequireHelpFirst makes the responder insist that the help message has
+// been shown before a response is accepted. This can be used for
+// consequential prompts, such as in a consent flow, to make sure that the
+// user has seen the full description of the choices. GetResponse will show
+// the help message automatically the first time that it is called. Where
+// the help is not shown automatically (see GetOnce and ResponseStream) any
+// response given before the help has been shown is rejected with a message
+// telling the user to press '?'. Once the help has been shown (by any copy
+// of the responder) responses are accepted as usual.
+//
+// This cannot be used with SetNoHelp.
+func SetRequireHelpFirst() RespOptFunc {
+	return func(r *R) error {
+		r.requireHelpFirst = true
+
+		return nil
+	}
+}
+
+// classifyHelpFirst classifies the input as for Classify but if the help
+// must be shown first and has not yet been shown then any response other
+// than a request for help is rejected.
+func (r R) classifyHelpFirst(input rune) (rune, Kind, error) {
+	resp, kind, err := r.Classify(input)
+	if err != nil || kind == KindHelp ||
+		!r.requireHelpFirst || r.state.helpShown {
+		return resp, kind, err
+	}
+
+	return unicode.ReplacementChar, KindInvalid,
+		fmt.Errorf("please read the help before answering"+
+			" - press '%c' to see it",
+			helpRune)
+}
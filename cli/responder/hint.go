@@ -0,0 +1,47 @@
+package responder
+
+import "fmt"
+
+// SetHint sets a short hint, for instance "(press ? for details)", which is
+// shown on its own line beneath the prompt the first time the prompt is
+// shown; it is not shown when the user is reprompted. The hint is
+// indented by the second indent (see SetIndents). If the output is a
+// terminal the cursor is moved back to the end of the prompt after the
+// hint is printed and the hint is cleared once a key has been read, before
+// anything else is printed. Otherwise the cursor cannot be moved back and
+// so the hint is printed on the line before the prompt. By default there
+// is no hint.
+func SetHint(hint string) RespOptFunc {
+	return func(r *R) error {
+		r.hint = hint
+
+		return nil
+	}
+}
+
+// printHintBefore prints the hint on its own line if the output is not a
+// terminal. It should be called before the prompt is printed.
+func (r R) printHintBefore(indent string) {
+	if r.hint == "" || r.outputIsTerminal() {
+		return
+	}
+
+	fmt.Fprintln(r.out, indent+r.hint)
+}
+
+// printHintBeneath prints the hint on the line beneath the prompt and then
+// moves the cursor back to the given column on the prompt line. It does
+// nothing unless the output is a terminal. It returns true if the hint was
+// printed.
+func (r R) printHintBeneath(indent string, col int) bool {
+	if r.hint == "" || !r.outputIsTerminal() {
+		return false
+	}
+
+	fmt.Fprint(r.out, "\n"+indent+r.hint+cursorUpSeq+"\r")
+	if col > 0 {
+		fmt.Fprintf(r.out, cursorRightFmt, col)
+	}
+
+	return true
+}
@@ -0,0 +1,104 @@
+package responder
+
+import "fmt"
+
+const (
+	firstHotkey = '1'
+	lastHotkey  = '9'
+)
+
+// SetNumericHotkeys makes the responder accept the digits 1 to 9 as
+// alternatives to the responses. The digits are assigned to the responses
+// in the order in which they are shown in the prompt, that is, the default
+// (if there is one) followed by the other responses in sorted order, so
+// that '1' selects the first response, '2' the second and so on. If there
+// are more than nine responses then the later ones have no numeric hotkey.
+// The hotkeys are shown alongside the responses in the prompt and in the
+// help message.
+//
+// None of the responses may be one of the digits 1 to 9; a response of '0'
+// is allowed and is matched as an ordinary response. There is therefore no
+// question of precedence between a digit response and a numeric hotkey.
+func SetNumericHotkeys() RespOptFunc {
+	return func(r *R) error {
+		for v := range r.validResps {
+			if v >= firstHotkey && v <= lastHotkey {
+				return fmt.Errorf(
+					"SetNumericHotkeys: the response '%c' is also"+
+						" used as a numeric hotkey",
+					v)
+			}
+		}
+
+		r.numericHotkeys = true
+
+		return nil
+	}
+}
+
+// hotkeyResp returns the response selected by the numeric hotkey and true
+// if the input is a numeric hotkey, otherwise it returns false.
+func (r R) hotkeyResp(input rune) (rune, bool) {
+	if !r.numericHotkeys || input < firstHotkey || input > lastHotkey {
+		return 0, false
+	}
+
+	keys := r.hotkeyOrder()
+	idx := int(input - firstHotkey)
+	if idx >= len(keys) {
+		return 0, false
+	}
+
+	return keys[idx], true
+}
+
+// hotkeyFor returns the numeric hotkey for the response and true if it has
+// one, otherwise it returns false.
+func (r R) hotkeyFor(resp rune) (rune, bool) {
+	if !r.numericHotkeys {
+		return 0, false
+	}
+
+	for i, k := range r.hotkeyOrder() {
+		if k == resp {
+			if i > int(lastHotkey-firstHotkey) {
+				return 0, false
+			}
+
+			return firstHotkey + rune(i), true
+		}
+	}
+
+	return 0, false
+}
+
+// hotkeyOrder returns the responses in the order in which the numeric
+// hotkeys are assigned. This is the order in which they are shown in the
+// prompt: the default first, if there is one, and then the others in sorted
+// order. The responder's default should already have been resolved.
+func (r R) hotkeyOrder() []rune {
+	keys := r.getSortedValidResponses()
+	if !r.hasDflt {
+		return keys
+	}
+
+	ordered := make([]rune, 0, len(keys))
+	ordered = append(ordered, r.dflt)
+	for _, k := range keys {
+		if k != r.dflt {
+			ordered = append(ordered, k)
+		}
+	}
+
+	return ordered
+}
+
+// promptKey returns the text used to show the response in the prompt. This
+// is the response followed by its numeric hotkey, if it has one.
+func (r R) promptKey(c rune) string {
+	if hk, ok := r.hotkeyFor(c); ok {
+		return fmt.Sprintf("%s(%c)", r.respText(c), hk)
+	}
+
+	return r.respText(c)
+}
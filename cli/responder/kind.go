@@ -0,0 +1,31 @@
+package responder
+
+// Kind describes the classification of a rune read from the user
+type Kind int
+
+const (
+	// KindInvalid means that the rune is not a valid response
+	KindInvalid Kind = iota
+	// KindValid means that the rune is one of the valid responses
+	KindValid
+	// KindDefault means that the rune selects the default response
+	KindDefault
+	// KindHelp means that the rune is a request for help
+	KindHelp
+)
+
+// String returns a string describing the Kind
+func (k Kind) String() string {
+	switch k {
+	case KindInvalid:
+		return "invalid"
+	case KindValid:
+		return "valid"
+	case KindDefault:
+		return "default"
+	case KindHelp:
+		return "help"
+	}
+
+	return "unknown"
+}
@@ -0,0 +1,301 @@
+package responder
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+// hybridWait is the time that a responder with SetHybridInput will wait
+// after an invalid letter for the next key of a word to arrive
+const hybridWait = 500 * time.Millisecond
+
+// SetLineMode makes the responder read a whole line rather than a single
+// rune. The terminal is not put into raw mode and so the user can edit the
+// line before pressing Enter. An empty line selects the default response,
+// if there is one. A line of a single character is checked in the same way
+// as a single rune would be.
+//
+// Otherwise the line is matched, ignoring case, against the start of the
+// descriptions of the responses. For instance, if the response 'y' has the
+// description "yes, delete the file" then the user may enter "yes". If the
+// line matches the start of more than one description then it is reported
+// as ambiguous and the user is asked again.
+//
+// This cannot be used with SetByteMode, SetTabCyclesDefault or
+// SetEscapeCancels.
+func SetLineMode() RespOptFunc {
+	return func(r *R) error {
+		r.lineMode = true
+
+		return nil
+	}
+}
+
+// SetHybridInput allows the user either to press a single key or to type a
+// word. A key which is a valid response (or which selects the default or
+// asks for help) is acted on immediately, as usual. If instead the user
+// types a letter which is not a valid response and more input is pending
+// then the responder switches to reading a line: the letter is echoed, the
+// rest of the line is read (with the terminal no longer in raw mode so the
+// user can edit it) and the whole word is matched, ignoring case, against
+// the start of the descriptions of the responses as for SetLineMode. If the
+// word matches no description, or matches more than one, then it is
+// reported as an error and the user is asked again.
+//
+// Input is taken to be pending if it has already been read or if it
+// arrives within a short time (half a second) of the letter. A lone letter
+// which is not a valid response is therefore reported as a bad response in
+// the usual way. If the input cannot be polled (for instance, it is not a
+// file or polling is not supported on this platform) the responder cannot
+// tell whether more input is pending and so it always switches to reading
+// a line.
+//
+// Note that a word starting with a letter which is itself a valid response
+// cannot be typed; the first letter will select that response.
+func SetHybridInput() RespOptFunc {
+	return func(r *R) error {
+		r.hybridInput = true
+
+		return nil
+	}
+}
+
+// inputPending reports whether more input is available, waiting for up to
+// hybridWait for it to arrive. If the input cannot be polled it returns
+// true.
+func (r R) inputPending() bool {
+	if r.rdr.Buffered() > 0 {
+		return true
+	}
+
+	pending, err := waitForInput(r.fd, hybridWait)
+
+	return err != nil || pending
+}
+
+// getHybridResp reads the rest of the line started by the first rune and
+// finds the response whose description it matches
+func (r R) getHybridResp(first rune) (rune, Kind, error) {
+	line, err := r.getLine(string(first))
+	if err != nil {
+		return unicode.ReplacementChar, KindInvalid, err
+	}
+
+	word := r.trimLine(line)
+
+	return r.matchLine(word)
+}
+
+// SetLineEditing makes the responder use the line editor from the
+// golang.org/x/term package when reading a line (see SetLineMode) from a
+// terminal. This lets the user move the cursor with the arrow keys and
+// recall earlier lines with the up and down arrows; the history is shared
+// by copies of the responder. If the input is not a terminal the line is
+// read as usual. With SetHybridInput the letter which started the word is
+// given to the editor and so it can be edited like the rest of the line.
+func SetLineEditing() RespOptFunc {
+	return func(r *R) error {
+		r.lineEditing = true
+
+		return nil
+	}
+}
+
+// lineEditor combines the responder's input and output so that they can be
+// used by the term package's line editor. The input is given to the editor
+// a byte at a time so that the editor never reads past the end of the line;
+// anything typed after Enter is left in the responder's reader to be read
+// by the next prompt. Any pending bytes are given to the editor first.
+type lineEditor struct {
+	rdr     *bufio.Reader
+	out     io.Writer
+	pending []byte
+}
+
+// Read gives the editor any pending bytes or else a single byte from the
+// responder's reader
+func (le *lineEditor) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	if len(le.pending) > 0 {
+		n := copy(p, le.pending)
+		le.pending = le.pending[n:]
+		return n, nil
+	}
+
+	b, err := le.rdr.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	p[0] = b
+
+	return 1, nil
+}
+
+// Write writes the editor's output to the responder's output
+func (le *lineEditor) Write(p []byte) (int, error) {
+	return le.out.Write(p)
+}
+
+// editLine reads a line using the term package's line editor. The
+// terminal is put into raw mode while the line is read. The line starts
+// with the prefill text, which the user can edit as if they had typed it.
+func (r R) editLine(prefill string) (string, error) {
+	restore, err := r.makeRaw(r.fd)
+	if err != nil {
+		return "", err
+	}
+	defer restore() //nolint: errcheck
+
+	if r.state.lineTerm == nil {
+		r.state.lineEd = &lineEditor{rdr: r.rdr, out: r.out}
+		r.state.lineTerm = term.NewTerminal(r.state.lineEd, "")
+	}
+	r.state.lineEd.pending = []byte(prefill)
+
+	return r.state.lineTerm.ReadLine()
+}
+
+// SetTrimInput controls whether leading and trailing whitespace is removed
+// from a line (see SetLineMode and SetHybridInput) before it is matched
+// against the responses. By default it is removed so that, for instance, a
+// stray trailing space does not stop the line from matching. Pass false if
+// the whitespace is significant.
+func SetTrimInput(trim bool) RespOptFunc {
+	return func(r *R) error {
+		r.noTrimInput = !trim
+
+		return nil
+	}
+}
+
+// trimLine removes any leading and trailing whitespace from the line unless
+// SetTrimInput(false) has been given
+func (r R) trimLine(line string) string {
+	if r.noTrimInput {
+		return line
+	}
+
+	return strings.TrimSpace(line)
+}
+
+// getLine reads a line of input and returns it without the trailing
+// newline. The line starts with the prefill text, which is echoed before
+// the rest of the line is read.
+func (r R) getLine(prefill string) (string, error) {
+	if err := r.waitForDeadline(); err != nil {
+		return "", err
+	}
+	if err := r.waitForDone(); err != nil {
+		return "", err
+	}
+
+	if r.lineEditing && r.fd != noFD && term.IsTerminal(r.fd) {
+		return r.editLine(prefill)
+	}
+
+	fmt.Fprint(r.out, prefill)
+
+	line, err := r.rdr.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil
+	}
+
+	return prefill + strings.TrimRight(line, "\r\n"), err
+}
+
+// getLineResp reads a line and finds the response it selects
+func (r R) getLineResp() (rune, Kind, error) {
+	line, err := r.getLine("")
+	if err != nil {
+		return unicode.ReplacementChar, KindInvalid, err
+	}
+
+	line = r.trimLine(line)
+
+	switch utf8.RuneCountInString(line) {
+	case 0:
+		if r.hasDflt {
+			return r.dflt, KindDefault, nil
+		}
+
+		return unicode.ReplacementChar, KindInvalid,
+			fmt.Errorf("bad response: nothing was entered")
+	case 1:
+		input, _ := utf8.DecodeRuneInString(line)
+
+		resp, kind, err := r.Classify(input)
+		if err == nil {
+			return resp, kind, nil
+		}
+
+		matched, ok, matchErr := r.matchDesc(line)
+		if matchErr != nil {
+			return matched, KindInvalid, matchErr
+		}
+		if ok {
+			return matched, KindValid, nil
+		}
+
+		return resp, kind, err
+	}
+
+	return r.matchLine(line)
+}
+
+// matchLine finds the response whose description the line matches
+func (r R) matchLine(line string) (rune, Kind, error) {
+	resp, ok, err := r.matchDesc(line)
+	if err != nil {
+		return resp, KindInvalid, err
+	}
+	if ok {
+		return resp, KindValid, nil
+	}
+
+	return unicode.ReplacementChar, KindInvalid,
+		fmt.Errorf("bad response: %s", line)
+}
+
+// matchDesc finds the response whose description starts with the word,
+// ignoring case. It returns the response and true if exactly one
+// description matches. If more than one description matches then an error
+// is returned.
+func (r R) matchDesc(word string) (rune, bool, error) {
+	word = strings.ToLower(word)
+
+	matches := []rune{}
+	for k := range r.validResps {
+		if strings.HasPrefix(strings.ToLower(r.description(k)), word) {
+			matches = append(matches, k)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return unicode.ReplacementChar, false, nil
+	case 1:
+		return matches[0], true, nil
+	}
+
+	sort.Slice(matches, func(i, j int) bool { return matches[i] < matches[j] })
+
+	descs := make([]string, 0, len(matches))
+	for _, m := range matches {
+		descs = append(descs, fmt.Sprintf("%c: %q", m, r.description(m)))
+	}
+
+	return unicode.ReplacementChar, false,
+		fmt.Errorf("ambiguous response: %q could be any of %s",
+			word, strings.Join(descs, ", "))
+}
@@ -0,0 +1,34 @@
+package responder
+
+import "time"
+
+// Metrics records how a single call to get a response went. It is passed
+// to the function set by SetMetrics.
+type Metrics struct {
+	// Duration is the time taken from first showing the prompt until the
+	// response was got or an error was detected
+	Duration time.Duration
+	// RepromptCount is the number of times the user was prompted again
+	// after giving an invalid response
+	RepromptCount int
+	// HelpRequested is true if the user asked for help
+	HelpRequested bool
+	// Outcome records how getting the response ended
+	Outcome Outcome
+	// Rune is the response returned. It will be the unicode ReplacementChar
+	// if an error was detected
+	Rune rune
+}
+
+// SetMetrics sets a function which is called at the end of each call to
+// GetResponse (or any of its variants) with the Metrics for that call. It
+// is called exactly once for each call, however many times the user was
+// prompted. This can be used to export the metrics to a monitoring
+// system. A nil function turns off the reporting of metrics.
+func SetMetrics(fn func(m Metrics)) RespOptFunc {
+	return func(r *R) error {
+		r.metricsFunc = fn
+
+		return nil
+	}
+}
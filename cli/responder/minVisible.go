@@ -0,0 +1,55 @@
+package responder
+
+import (
+	"fmt"
+	"time"
+)
+
+const bellSeq = "\a"
+
+// SetMinVisibleTime sets the minimum time for which the prompt must have
+// been shown before a key press is accepted. Any key pressed before then is
+// ignored. This guards against the user answering a prompt which has just
+// appeared by pressing a key out of habit and so is useful for prompts with
+// serious consequences. The time must be greater than zero.
+//
+// This applies to GetResponse and GetOnce and the methods which call them.
+func SetMinVisibleTime(d time.Duration) RespOptFunc {
+	return func(r *R) error {
+		if d <= 0 {
+			return fmt.Errorf(
+				"SetMinVisibleTime: the time (%s) must be greater than 0",
+				d)
+		}
+
+		r.minVisible = d
+
+		return nil
+	}
+}
+
+// SetBeepOnEarlyKey makes the responder sound the terminal bell when a key
+// is ignored because it was pressed before the prompt had been shown for
+// the time given by SetMinVisibleTime.
+func SetBeepOnEarlyKey() RespOptFunc {
+	return func(r *R) error {
+		r.beepOnEarlyKey = true
+
+		return nil
+	}
+}
+
+// isEarly reports whether a key read now would be too early to accept. It
+// sounds the bell if SetBeepOnEarlyKey has been given.
+func (r R) isEarly() bool {
+	if r.minVisible == 0 || r.promptTime.IsZero() ||
+		time.Since(r.promptTime) >= r.minVisible {
+		return false
+	}
+
+	if r.beepOnEarlyKey {
+		fmt.Fprint(r.out, bellSeq)
+	}
+
+	return true
+}
@@ -0,0 +1,70 @@
+package responder
+
+import "fmt"
+
+// SetInteractiveDefault sets the default response to be used when the
+// responder is reading from a terminal (see R.IsInteractive). It takes
+// precedence over any default given by SetDefault but not over a default
+// function (see SetDefaultFunc). The default must be in the list of valid
+// responses.
+func SetInteractiveDefault(d rune) RespOptFunc {
+	return func(r *R) error {
+		if _, ok := r.validResps[d]; !ok {
+			return fmt.Errorf(
+				"SetInteractiveDefault: the default response (%c) is not"+
+					" in the list of valid responses",
+				d)
+		}
+
+		r.interDflt = d
+		r.hasInterDflt = true
+
+		return nil
+	}
+}
+
+// SetNonInteractiveDefault sets the default response to be used when the
+// responder is not reading from a terminal (see R.IsInteractive). It takes
+// precedence over any default given by SetDefault but not over a default
+// function (see SetDefaultFunc). The default must be in the list of valid
+// responses.
+//
+// Note that the prompt shows the default for the current mode and so the
+// user will only see this default if the prompt is shown when not reading
+// from a terminal.
+func SetNonInteractiveDefault(d rune) RespOptFunc {
+	return func(r *R) error {
+		if _, ok := r.validResps[d]; !ok {
+			return fmt.Errorf(
+				"SetNonInteractiveDefault: the default response (%c) is not"+
+					" in the list of valid responses",
+				d)
+		}
+
+		r.nonInterDflt = d
+		r.hasNonInterDflt = true
+
+		return nil
+	}
+}
+
+// resolveModeDefault returns a copy of the responder with the default set
+// from the interactive or non-interactive default, as appropriate. If the
+// appropriate default is not set the copy is unchanged.
+func (r R) resolveModeDefault() R {
+	if !r.hasInterDflt && !r.hasNonInterDflt {
+		return r
+	}
+
+	if r.IsInteractive() {
+		if r.hasInterDflt {
+			r.dflt = r.interDflt
+			r.hasDflt = true
+		}
+	} else if r.hasNonInterDflt {
+		r.dflt = r.nonInterDflt
+		r.hasDflt = true
+	}
+
+	return r
+}
@@ -0,0 +1,90 @@
+package responder
+
+import "fmt"
+
+// OptionalResponder prompts the user only if the responder is reading from
+// a terminal (see R.IsInteractive). Otherwise it returns the fallback
+// response immediately without reading anything. This is useful for code
+// which should ask the user when possible but which must never wait for a
+// response when run non-interactively, for instance in a CI pipeline.
+type OptionalResponder struct {
+	r        *R
+	fallback rune
+}
+
+// NewOptional creates an OptionalResponder. The responses and options are
+// checked as for the New function and the fallback must be in the list of
+// valid responses.
+func NewOptional(
+	prompt string,
+	responses map[rune]string,
+	fallback rune,
+	opts ...RespOptFunc,
+) (*OptionalResponder, error) {
+	r, err := New(prompt, responses, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := r.validResps[fallback]; !ok {
+		return nil,
+			fmt.Errorf(
+				"the fallback response (%c) is not"+
+					" in the list of valid responses",
+				fallback)
+	}
+
+	return &OptionalResponder{
+		r:        r,
+		fallback: fallback,
+	}, nil
+}
+
+// NewOptionalOrPanic creates a new OptionalResponder and panics if there
+// are any errors
+func NewOptionalOrPanic(
+	prompt string,
+	responses map[rune]string,
+	fallback rune,
+	opts ...RespOptFunc,
+) *OptionalResponder {
+	or, err := NewOptional(prompt, responses, fallback, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return or
+}
+
+// GetResponse returns the fallback response if the responder is not
+// reading from a terminal, otherwise it calls the GetResponse method on the
+// underlying R.
+func (or OptionalResponder) GetResponse() (rune, error) {
+	return or.GetResponseIndent(or.r.indentFirst, or.r.indent)
+}
+
+// GetResponseOrDie calls GetResponse to get the response but if there is an
+// error it will print it and exit with status 1.
+func (or OptionalResponder) GetResponseOrDie() rune {
+	return or.GetResponseIndentOrDie(or.r.indentFirst, or.r.indent)
+}
+
+// GetResponseIndent behaves as GetResponse but the indents are taken from
+// the parameters rather than the responder.
+func (or OptionalResponder) GetResponseIndent(first, second int) (rune, error) {
+	if !or.r.IsInteractive() {
+		return or.fallback, nil
+	}
+
+	return or.r.GetResponseIndent(first, second)
+}
+
+// GetResponseIndentOrDie calls GetResponseIndent to get the response but if
+// there is an error it will print it and exit with status 1.
+func (or OptionalResponder) GetResponseIndentOrDie(first, second int) rune {
+	resp, err := or.GetResponseIndent(first, second)
+	if err != nil {
+		or.r.reportErrAndExit(err)
+	}
+
+	return resp
+}
@@ -0,0 +1,60 @@
+package responder
+
+// Outcome describes how an attempt to get a response ended
+type Outcome int
+
+const (
+	// OutcomeReadError means that the input could not be read, including
+	// reaching the end of the input, or that some other error not covered
+	// by the other outcomes was detected
+	OutcomeReadError Outcome = iota
+	// OutcomeAnswered means that the user chose a valid response
+	OutcomeAnswered
+	// OutcomeDefaultSelected means that the user selected the default
+	// response by entering whitespace
+	OutcomeDefaultSelected
+	// OutcomeAborted means that the user chose the abort response (see
+	// SetAbortResponse)
+	OutcomeAborted
+	// OutcomeCancelled means that the user or the caller cancelled the
+	// prompt (see SetEscapeCancels and GetResponseWithCancel)
+	OutcomeCancelled
+	// OutcomeTimedOut means that no response was given before the deadline
+	// (see GetResponseDeadline)
+	OutcomeTimedOut
+	// OutcomeTooManyReprompts means that the user did not give a valid
+	// response within the number of reprompts allowed (see
+	// SetMaxReprompts)
+	OutcomeTooManyReprompts
+)
+
+// String returns a string describing the Outcome
+func (o Outcome) String() string {
+	switch o {
+	case OutcomeReadError:
+		return "read error"
+	case OutcomeAnswered:
+		return "answered"
+	case OutcomeDefaultSelected:
+		return "default selected"
+	case OutcomeAborted:
+		return "aborted"
+	case OutcomeCancelled:
+		return "cancelled"
+	case OutcomeTimedOut:
+		return "timed out"
+	case OutcomeTooManyReprompts:
+		return "too many reprompts"
+	}
+
+	return "unknown"
+}
+
+// GetResponseE behaves as GetResponseIndent but also returns the outcome of
+// asking for the response. This allows the caller to handle each of the
+// ways in which getting a response can end with a single switch
+// statement. The error is also returned to give the details of any
+// problem.
+func (r R) GetResponseE(first, second int) (rune, Outcome, error) {
+	return r.getResponse(first, second)
+}
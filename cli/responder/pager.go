@@ -0,0 +1,62 @@
+package responder
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// SetHelpPager sets a command (for instance "less -R") through which the
+// help message will be shown when the user asks for help. This is useful
+// when the help is too long to fit on the screen. The command is split into
+// words on white space; the first word is the program to run and the rest
+// are its arguments. The help text is passed to the command on its standard
+// input.
+//
+// The terminal is restored from raw mode while the pager runs. If the pager
+// cannot be run then the help is printed as usual. This cannot be used with
+// SetNoHelp.
+func SetHelpPager(cmd string) RespOptFunc {
+	return func(r *R) error {
+		parts := strings.Fields(cmd)
+		if len(parts) == 0 {
+			return fmt.Errorf("SetHelpPager: the pager command must not be empty")
+		}
+
+		r.helpPager = parts
+
+		return nil
+	}
+}
+
+// showHelp shows the help message, passing it through the pager if one has
+// been set. If the pager fails the help is printed directly. The terminal
+// is not in raw mode when this is called unless it is being read by
+// RunPrompts, in which case it is restored while the pager runs.
+func (r R) showHelp(indent int) {
+	if len(r.helpPager) == 0 {
+		r.PrintHelpIndent(indent)
+		return
+	}
+
+	var buf bytes.Buffer
+	pr := r
+	pr.out = &buf
+	pr.PrintHelpIndent(indent)
+
+	cmd := exec.Command(r.helpPager[0], r.helpPager[1:]...) //nolint: gosec
+	cmd.Stdin = &buf
+	cmd.Stdout = unwrapCRLF(r.out)
+	cmd.Stderr = os.Stderr
+
+	resume := r.suspendRaw()
+	err := cmd.Run()
+	resume()
+	if err != nil {
+		r.record("error", fmt.Sprintf("the help pager (%s) failed: %v",
+			r.helpPager[0], err))
+		r.PrintHelpIndent(indent)
+	}
+}
@@ -0,0 +1,50 @@
+package responder
+
+import "golang.org/x/term"
+
+// SetConsumeLineOnPaste makes the responder discard the rest of the
+// current line of input after a single key has been accepted as a valid
+// response. This stops the remaining characters of a word or line pasted
+// in response to the prompt from being taken as the answers to later
+// prompts. Only input that has already arrived is discarded; the responder
+// does not wait for more. This only applies when the input is a terminal.
+//
+// Input which is read as more than one key, such as a code read by
+// GetFixedLength or a word typed in hybrid input mode (see
+// SetHybridInput), is read in full.
+func SetConsumeLineOnPaste() RespOptFunc {
+	return func(r *R) error {
+		r.consumeLine = true
+
+		return nil
+	}
+}
+
+// isLineEnd reports whether the character ends a line; in raw mode the Enter
+// key gives a carriage return rather than a newline
+func isLineEnd(c rune) bool {
+	return c == '\n' || c == '\r'
+}
+
+// discardLine discards any input which is already available up to and
+// including the end of the current line. It does nothing unless
+// SetConsumeLineOnPaste has been given and the input is a terminal.
+func (r R) discardLine() {
+	if !r.consumeLine || r.fd == noFD || !term.IsTerminal(r.fd) {
+		return
+	}
+
+	for {
+		if r.rdr.Buffered() == 0 {
+			pending, err := waitForInput(r.fd, 0)
+			if err != nil || !pending {
+				return
+			}
+		}
+
+		b, err := r.rdr.ReadByte()
+		if err != nil || isLineEnd(rune(b)) {
+			return
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package responder
+
+import "errors"
+
+// InputPending reports whether there is input waiting to be read, for
+// instance because the user has typed ahead of the prompt. The input is
+// not consumed.
+//
+// This only works when the input is a terminal or some other file which can
+// be polled; if the input was set from a plain io.Reader (see SetInput)
+// then, unless some input has already been buffered, an error is
+// returned. An error is also returned on platforms where polling is not
+// supported.
+func (r R) InputPending() (bool, error) {
+	if r.rdr.Buffered() > 0 {
+		return true, nil
+	}
+
+	if r.fd == noFD {
+		return false,
+			errors.New("cannot check for pending input: the input is not a file")
+	}
+
+	return waitForInput(r.fd, 0)
+}
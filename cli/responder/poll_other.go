@@ -0,0 +1,15 @@
+//go:build !(aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris || windows)
+
+package responder
+
+import (
+	"errors"
+	"time"
+)
+
+// waitForInput is not supported on this platform and always returns an
+// error. Note that this means that any Escape character is taken as a lone
+// Escape (see SetEscapeCancels).
+func waitForInput(_ int, _ time.Duration) (bool, error) {
+	return false, errors.New("polling for input is not supported")
+}
@@ -0,0 +1,34 @@
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+
+package responder
+
+import (
+	"errors"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// waitForInput waits for up to the timeout for input to be available on the
+// file descriptor. It returns true if there is input available.
+func waitForInput(fd int, timeout time.Duration) (bool, error) {
+	if fd < 0 {
+		return false, errors.New("there is no file descriptor to poll")
+	}
+
+	fds := []unix.PollFd{{Fd: int32(fd), Events: unix.POLLIN}}
+	for {
+		n, err := unix.Poll(fds, int(timeout/time.Millisecond))
+		if errors.Is(err, unix.EINTR) {
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+		if fds[0].Revents&unix.POLLNVAL != 0 {
+			return false, errors.New("the file descriptor is not open")
+		}
+
+		return n > 0, nil
+	}
+}
@@ -0,0 +1,109 @@
+//go:build windows
+
+package responder
+
+import (
+	"errors"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	kernel32              = windows.NewLazySystemDLL("kernel32.dll")
+	procPeekConsoleInputW = kernel32.NewProc("PeekConsoleInputW")
+	procReadConsoleInputW = kernel32.NewProc("ReadConsoleInputW")
+)
+
+// keyEvent is the event type of an input record holding a key event
+const keyEvent = 0x0001
+
+// inputRecord is the Windows INPUT_RECORD structure. The event is a union
+// but only key events are examined and so it is given as the layout of a
+// KEY_EVENT_RECORD, which is the largest member.
+type inputRecord struct {
+	eventType       uint16
+	_               uint16
+	keyDown         int32
+	repeatCount     uint16
+	virtualKeyCode  uint16
+	virtualScanCode uint16
+	unicodeChar     uint16
+	controlKeyState uint32
+}
+
+// isCharKey returns true if the record is for a key being pressed which
+// gives a character to be read. Other records (key releases, focus and
+// mouse events and keys, such as Shift, which give no character) do not
+// give anything to read.
+func (rec inputRecord) isCharKey() bool {
+	return rec.eventType == keyEvent && rec.keyDown != 0 && rec.unicodeChar != 0
+}
+
+// waitForInput waits for up to the timeout for input to be available on the
+// console handle. It returns true if there is input available. A Windows
+// console signals that input is available for events other than key
+// presses (for instance, focus changes) and so these events are read and
+// discarded until a key press is found or the timeout is reached. If the
+// handle is not a console the events cannot be examined and any signalled
+// input is taken as available.
+func waitForInput(fd int, timeout time.Duration) (bool, error) {
+	if fd < 0 {
+		return false, errors.New("there is no file descriptor to poll")
+	}
+
+	h := windows.Handle(fd)
+	deadline := time.Now().Add(timeout)
+
+	for {
+		event, err := windows.WaitForSingleObject(
+			h, uint32(timeout/time.Millisecond))
+		if err != nil {
+			return false, err
+		}
+		if event != windows.WAIT_OBJECT_0 {
+			return false, nil
+		}
+
+		found, err := discardNonKeyEvents(h)
+		if err != nil || found {
+			return true, nil //nolint: nilerr
+		}
+
+		timeout = time.Until(deadline)
+		if timeout < 0 {
+			return false, nil
+		}
+	}
+}
+
+// discardNonKeyEvents reads and discards the pending console input records
+// until one is found which gives a character to read; that record is left
+// to be read. It returns true if such a record was found and an error if
+// the records cannot be examined (for instance, if the handle is not a
+// console).
+func discardNonKeyEvents(h windows.Handle) (bool, error) {
+	var rec inputRecord
+	var n uint32
+
+	for {
+		ok, _, err := procPeekConsoleInputW.Call(uintptr(h),
+			uintptr(unsafe.Pointer(&rec)), 1, uintptr(unsafe.Pointer(&n)))
+		if ok == 0 {
+			return false, err
+		}
+		if n == 0 {
+			return false, nil
+		}
+		if rec.isCharKey() {
+			return true, nil
+		}
+
+		ok, _, err = procReadConsoleInputW.Call(uintptr(h),
+			uintptr(unsafe.Pointer(&rec)), 1, uintptr(unsafe.Pointer(&n)))
+		if ok == 0 {
+			return false, err
+		}
+	}
+}
@@ -0,0 +1,63 @@
+//go:build windows
+
+package responder
+
+import (
+	"testing"
+	"unsafe"
+)
+
+func TestInputRecordSize(t *testing.T) {
+	// the size of the Windows INPUT_RECORD structure
+	const expSize = 20
+
+	if s := unsafe.Sizeof(inputRecord{}); s != expSize {
+		t.Errorf("expected an inputRecord to be %d bytes, got: %d",
+			expSize, s)
+	}
+}
+
+func TestIsCharKey(t *testing.T) {
+	const focusEvent = 0x0010
+
+	testCases := []struct {
+		name   string
+		rec    inputRecord
+		expKey bool
+	}{
+		{
+			name: "key down",
+			rec: inputRecord{
+				eventType:   keyEvent,
+				keyDown:     1,
+				unicodeChar: 'y',
+			},
+			expKey: true,
+		},
+		{
+			name: "key up",
+			rec:  inputRecord{eventType: keyEvent, unicodeChar: 'y'},
+		},
+		{
+			name: "key down, no character",
+			rec:  inputRecord{eventType: keyEvent, keyDown: 1},
+		},
+		{
+			name: "focus event",
+			rec:  inputRecord{eventType: focusEvent},
+		},
+	}
+
+	for _, tc := range testCases {
+		if k := tc.rec.isCharKey(); k != tc.expKey {
+			t.Errorf("%s: expected isCharKey to be %t, got: %t",
+				tc.name, tc.expKey, k)
+		}
+	}
+}
+
+func TestWaitForInputNoHandle(t *testing.T) {
+	if _, err := waitForInput(-1, 0); err == nil {
+		t.Error("expected an error for a negative file descriptor")
+	}
+}
@@ -0,0 +1,63 @@
+package responder
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// The placeholders which may be used in a prompt template
+const (
+	tmplPrompt    = "{prompt}"
+	tmplResponses = "{responses}"
+	tmplDefault   = "{default}"
+)
+
+// tmplPlaceholderRE matches anything that looks like a placeholder
+var tmplPlaceholderRE = regexp.MustCompile(`\{[^{}]*\}`)
+
+// SetPromptTemplate sets a template which is used to generate the prompt
+// in place of the standard layout. The template may contain the following
+// placeholders:
+//
+//	{prompt}     the prompt (or the result of the prompt function)
+//	{responses}  the valid responses separated by slashes, as they would
+//	             appear between the parentheses of the standard layout
+//	{default}    the default response or the empty string if there is none
+//
+// The standard layout is equivalent to "{prompt}? ({responses}): ". The
+// template must not contain any other placeholders.
+func SetPromptTemplate(tmpl string) RespOptFunc {
+	return func(r *R) error {
+		for _, ph := range tmplPlaceholderRE.FindAllString(tmpl, -1) {
+			switch ph {
+			case tmplPrompt, tmplResponses, tmplDefault:
+			default:
+				return fmt.Errorf(
+					"SetPromptTemplate: unknown placeholder %q"+
+						" - allowed values are: %s, %s, %s",
+					ph, tmplPrompt, tmplResponses, tmplDefault)
+			}
+		}
+
+		r.promptTmpl = tmpl
+
+		return nil
+	}
+}
+
+// renderPromptTmpl returns the prompt generated from the prompt template
+func (r R) renderPromptTmpl() string {
+	r, _ = r.resolveDefault()
+
+	dflt := ""
+	if r.hasDflt {
+		dflt = string(r.dflt)
+	}
+
+	return strings.NewReplacer(
+		tmplPrompt, r.promptValue(),
+		tmplResponses, r.responsesList(),
+		tmplDefault, dflt,
+	).Replace(r.promptTmpl)
+}
@@ -0,0 +1,1131 @@
+package responder
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+	"unicode"
+	"unsafe"
+
+	"golang.org/x/term"
+)
+
+// ioctl performs the ioctl system call
+func ioctl(fd, req, arg uintptr) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, req, arg)
+	if errno != 0 {
+		return errno
+	}
+
+	return nil
+}
+
+// openPty opens a new pseudo-terminal pair, returning the master and slave
+// files
+func openPty(t *testing.T) (*os.File, *os.File) {
+	t.Helper()
+
+	master, err := os.OpenFile("/dev/ptmx", os.O_RDWR, 0)
+	if err != nil {
+		t.Skipf("cannot open a pseudo-terminal: %v", err)
+	}
+
+	var unlock int32
+	err = ioctl(master.Fd(), syscall.TIOCSPTLCK, uintptr(unsafe.Pointer(&unlock)))
+	if err != nil {
+		master.Close()
+		t.Skipf("cannot unlock the pseudo-terminal: %v", err)
+	}
+
+	var ptyNum uint32
+	err = ioctl(master.Fd(), syscall.TIOCGPTN, uintptr(unsafe.Pointer(&ptyNum)))
+	if err != nil {
+		master.Close()
+		t.Skipf("cannot get the pseudo-terminal number: %v", err)
+	}
+
+	slave, err := os.OpenFile(fmt.Sprintf("/dev/pts/%d", ptyNum),
+		os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		master.Close()
+		t.Skipf("cannot open the pseudo-terminal slave: %v", err)
+	}
+
+	return master, slave
+}
+
+func TestPtyRawMode(t *testing.T) {
+	master, slave := openPty(t)
+	defer master.Close()
+	defer slave.Close()
+
+	before, err := term.GetState(int(slave.Fd()))
+	if err != nil {
+		t.Fatalf("cannot get the terminal state: %v", err)
+	}
+
+	r := NewOrPanic("test",
+		map[rune]string{
+			'y': "yes",
+			'n': "no",
+		},
+		SetFile(slave),
+		SetOutput(io.Discard))
+
+	if !r.IsInteractive() {
+		t.Fatal("the pseudo-terminal should be interactive")
+	}
+
+	type result struct {
+		resp rune
+		err  error
+	}
+	resCh := make(chan result, 1)
+	go func() {
+		resp, err := r.GetResponse()
+		resCh <- result{resp: resp, err: err}
+	}()
+
+	// with no trailing newline this will only be read if the terminal is
+	// in raw mode
+	if _, err = master.Write([]byte("y")); err != nil {
+		t.Fatalf("cannot write to the pseudo-terminal: %v", err)
+	}
+
+	select {
+	case res := <-resCh:
+		if res.resp != 'y' || res.err != nil {
+			t.Errorf("expected 'y' and no error, got: %q, %v",
+				res.resp, res.err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the response" +
+			" - the terminal was not put into raw mode")
+	}
+
+	after, err := term.GetState(int(slave.Fd()))
+	if err != nil {
+		t.Fatalf("cannot get the terminal state: %v", err)
+	}
+	if *before != *after {
+		t.Error("the terminal state was not restored")
+	}
+}
+
+func TestPtyResponseColor(t *testing.T) {
+	master, slave := openPty(t)
+	defer master.Close()
+	defer slave.Close()
+
+	resps := map[rune]string{
+		'd': "delete",
+		'k': "keep",
+	}
+
+	for _, out := range []io.Writer{slave, io.Discard} {
+		r := NewOrPanic("test", resps,
+			SetDefault('d'),
+			SetResponseColor('d', "1;31"),
+			SetOutput(out))
+
+		expColor := out == io.Writer(slave)
+		gotColor := strings.Contains(r.PromptString(), "\x1b[1;31md\x1b[0m")
+		if gotColor != expColor {
+			t.Errorf("output is a terminal: %t, coloured response: %t"+
+				" - prompt: %q",
+				expColor, gotColor, r.PromptString())
+		}
+
+		if r.PromptWidth() != len("test? ([d]/k/?): ") {
+			t.Errorf("the prompt width includes the colour: %d",
+				r.PromptWidth())
+		}
+	}
+}
+
+func TestPtyRunPrompts(t *testing.T) {
+	master, slave := openPty(t)
+	defer master.Close()
+	defer slave.Close()
+
+	rawCount := 0
+	countRaw := func(fd int) (func() error, error) {
+		rawCount++
+		return termMakeRaw(fd)
+	}
+
+	// the same responder is used twice so that both responses are read
+	// through the same buffered reader
+	r := NewOrPanic("test",
+		map[rune]string{
+			'y': "yes",
+			'n': "no",
+		},
+		SetFile(slave), SetOutput(io.Discard), SetRawModeFuncs(countRaw))
+	rs := []*R{r, r}
+
+	if _, err := master.Write([]byte("yn")); err != nil {
+		t.Fatalf("cannot write to the pseudo-terminal: %v", err)
+	}
+
+	got, err := RunPrompts(rs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(got) != "yn" {
+		t.Errorf("expected responses \"yn\", got: %q", string(got))
+	}
+	if rawCount != 1 {
+		t.Errorf("expected raw mode to be set once, it was set %d times",
+			rawCount)
+	}
+
+	// now check that newlines written to the output and the error output
+	// are written as carriage return, newline pairs
+	r = NewOrPanic("test",
+		map[rune]string{
+			'y': "yes",
+			'n': "no",
+		},
+		SetFile(slave), SetOutput(slave), SetErrOutput(slave))
+
+	const endMark = "END"
+
+	outCh := make(chan string, 1)
+	go func() {
+		var b strings.Builder
+		buf := make([]byte, 256)
+		for !strings.HasSuffix(b.String(), endMark) {
+			n, err := master.Read(buf)
+			if err != nil {
+				break
+			}
+			b.Write(buf[:n])
+		}
+		outCh <- b.String()
+	}()
+
+	if _, err = master.Write([]byte("zy")); err != nil {
+		t.Fatalf("cannot write to the pseudo-terminal: %v", err)
+	}
+
+	got, err = RunPrompts([]*R{r})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(got) != "y" {
+		t.Errorf("expected response \"y\", got: %q", string(got))
+	}
+	fmt.Fprint(slave, endMark)
+
+	select {
+	case out := <-outCh:
+		if !strings.Contains(out, "bad response: z") {
+			t.Errorf("the error was not written: %q", out)
+		}
+		if strings.Count(out, "\n") != strings.Count(out, "\r\n") {
+			t.Errorf("a newline was written without a carriage return: %q",
+				out)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the output")
+	}
+}
+
+func TestPtyAutoIndent(t *testing.T) {
+	master, slave := openPty(t)
+	defer master.Close()
+	defer slave.Close()
+
+	// rows, columns, x pixels, y pixels
+	winSize := [4]uint16{24, 120, 0, 0}
+	err := ioctl(slave.Fd(), syscall.TIOCSWINSZ,
+		uintptr(unsafe.Pointer(&winSize)))
+	if err != nil {
+		t.Skipf("cannot set the pseudo-terminal size: %v", err)
+	}
+
+	testCases := []struct {
+		name     string
+		opts     []RespOptFunc
+		expFirst int
+		expOther int
+	}{
+		{
+			name:     "auto",
+			opts:     []RespOptFunc{SetOutput(slave), SetAutoIndent()},
+			expFirst: 6,
+			expOther: 6,
+		},
+		{
+			name: "explicit indents win",
+			opts: []RespOptFunc{
+				SetOutput(slave),
+				SetIndents(1, 2),
+				SetAutoIndent(),
+			},
+			expFirst: 1,
+			expOther: 2,
+		},
+		{
+			name:     "not a terminal",
+			opts:     []RespOptFunc{SetOutput(io.Discard), SetAutoIndent()},
+			expFirst: 0,
+			expOther: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		r := NewOrPanic("test",
+			map[rune]string{
+				'y': "yes",
+				'n': "no",
+			},
+			tc.opts...)
+
+		first, other := r.Indents()
+		if first != tc.expFirst || other != tc.expOther {
+			t.Errorf("%s: expected indents %d, %d, got: %d, %d",
+				tc.name, tc.expFirst, tc.expOther, first, other)
+		}
+	}
+}
+
+func TestPtyConsumeLineOnPaste(t *testing.T) {
+	master, slave := openPty(t)
+	defer master.Close()
+	defer slave.Close()
+
+	r := NewOrPanic("test",
+		map[rune]string{
+			'y': "yes",
+			'n': "no",
+		},
+		SetFile(slave), SetOutput(io.Discard), SetConsumeLineOnPaste())
+
+	// put the terminal into raw mode before writing so that the input is
+	// available to be read without a trailing newline
+	state, err := term.MakeRaw(int(slave.Fd()))
+	if err != nil {
+		t.Fatalf("cannot put the terminal into raw mode: %v", err)
+	}
+	defer term.Restore(int(slave.Fd()), state) //nolint: errcheck
+
+	if _, err = master.Write([]byte("yes\rn")); err != nil {
+		t.Fatalf("cannot write to the pseudo-terminal: %v", err)
+	}
+
+	for _, exp := range []rune{'y', 'n'} {
+		resp, _, err := r.getResp()
+		if resp != exp || err != nil {
+			t.Errorf("expected %q and no error, got: %q, %v", exp, resp, err)
+		}
+	}
+}
+
+func TestPtyGetResponseWithCancel(t *testing.T) {
+	master, slave := openPty(t)
+	defer master.Close()
+	defer slave.Close()
+
+	before, err := term.GetState(int(slave.Fd()))
+	if err != nil {
+		t.Fatalf("cannot get the terminal state: %v", err)
+	}
+
+	r := NewOrPanic("test",
+		map[rune]string{
+			'y': "yes",
+			'n': "no",
+		},
+		SetFile(slave),
+		SetOutput(io.Discard))
+
+	type result struct {
+		resp rune
+		err  error
+	}
+	resCh := make(chan result, 1)
+	done := make(chan struct{})
+	go func() {
+		resp, err := r.GetResponseWithCancel(done, 0, 0)
+		resCh <- result{resp: resp, err: err}
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	close(done)
+
+	select {
+	case res := <-resCh:
+		if !errors.Is(res.err, ErrCancelled) {
+			t.Errorf("expected ErrCancelled, got: %q, %v", res.resp, res.err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the prompt to be cancelled")
+	}
+
+	after, err := term.GetState(int(slave.Fd()))
+	if err != nil {
+		t.Fatalf("cannot get the terminal state: %v", err)
+	}
+	if *before != *after {
+		t.Error("the terminal state was not restored")
+	}
+}
+
+func TestPtyColumnarPrompt(t *testing.T) {
+	master, slave := openPty(t)
+	defer master.Close()
+	defer slave.Close()
+
+	// rows, columns, x pixels, y pixels
+	winSize := [4]uint16{24, 40, 0, 0}
+	err := ioctl(slave.Fd(), syscall.TIOCSWINSZ,
+		uintptr(unsafe.Pointer(&winSize)))
+	if err != nil {
+		t.Skipf("cannot set the pseudo-terminal size: %v", err)
+	}
+
+	resps := map[rune]string{
+		'a': "add",
+		'b': "build",
+		'c': "clean",
+		'd': "deploy",
+		'e': "edit",
+		'f': "fetch",
+	}
+
+	r := NewOrPanic("test", resps,
+		SetDefault('b'),
+		SetColumnarPrompt(),
+		SetOutput(slave))
+
+	if vrs := r.ValidResponsesString(); vrs != "" {
+		t.Errorf("the responses should not be in the prompt, got: %q", vrs)
+	}
+	if p := r.PromptString(); p != "test? " {
+		t.Errorf("expected the prompt %q, got: %q", "test? ", p)
+	}
+	if w := r.PromptWidth(); w != len("test? ") {
+		t.Errorf("expected the prompt width %d, got: %d", len("test? "), w)
+	}
+
+	expOut := "" +
+		"   a  add          e  edit\n" +
+		"  [b] build        f  fetch\n" +
+		"   c  clean        ?  show help\n" +
+		"   d  deploy\n"
+
+	const endMark = "END"
+
+	outCh := make(chan string, 1)
+	go func() {
+		var b strings.Builder
+		buf := make([]byte, 256)
+		for !strings.HasSuffix(b.String(), endMark) {
+			n, err := master.Read(buf)
+			if err != nil {
+				break
+			}
+			b.WriteString(strings.ReplaceAll(string(buf[:n]), "\r", ""))
+		}
+		outCh <- b.String()
+	}()
+
+	r.printColumns("  ")
+	fmt.Fprint(slave, endMark)
+
+	select {
+	case out := <-outCh:
+		out = strings.TrimSuffix(out, endMark)
+		if out != expOut {
+			t.Errorf("expected columns:\n%q\ngot:\n%q", expOut, out)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the columns")
+	}
+
+	narrow := NewOrPanic("test", resps,
+		SetColumnarPrompt(),
+		SetDescriptionFunc(func(c rune) string {
+			return strings.Repeat(resps[c]+" ", 5)
+		}),
+		SetOutput(slave))
+	if narrow.useColumns() {
+		t.Error("the terminal is too narrow for columns")
+	}
+}
+
+func TestPtyConsumeLineOnPasteMultiKey(t *testing.T) {
+	master, slave := openPty(t)
+	defer master.Close()
+	defer slave.Close()
+
+	// put the terminal into raw mode before writing so that the input is
+	// available to be read without a trailing newline
+	state, err := term.MakeRaw(int(slave.Fd()))
+	if err != nil {
+		t.Fatalf("cannot put the terminal into raw mode: %v", err)
+	}
+	defer term.Restore(int(slave.Fd()), state) //nolint: errcheck
+
+	if _, err = master.Write([]byte("gb")); err != nil {
+		t.Fatalf("cannot write to the pseudo-terminal: %v", err)
+	}
+
+	code, err := GetFixedLength("test", 2,
+		map[string]string{
+			"gb": "United Kingdom",
+			"fr": "France",
+		},
+		SetFile(slave), SetOutput(io.Discard), SetConsumeLineOnPaste())
+	if code != "gb" || err != nil {
+		t.Errorf("fixed length: expected \"gb\" and no error, got: %q, %v",
+			code, err)
+	}
+
+	r := NewOrPanic("test",
+		map[rune]string{
+			'x': "remove",
+			'k': "keep",
+		},
+		SetFile(slave), SetOutput(io.Discard),
+		SetHybridInput(), SetConsumeLineOnPaste())
+
+	if _, err = master.Write([]byte("remove\n")); err != nil {
+		t.Fatalf("cannot write to the pseudo-terminal: %v", err)
+	}
+
+	resp, _, err := r.getResp()
+	if resp != 'x' || err != nil {
+		t.Errorf("hybrid input: expected 'x' and no error, got: %q, %v",
+			resp, err)
+	}
+}
+
+func TestPtyLineEditing(t *testing.T) {
+	master, slave := openPty(t)
+	defer master.Close()
+	defer slave.Close()
+
+	resps := map[rune]string{
+		'x': "remove",
+		'k': "keep",
+	}
+
+	testCases := []struct {
+		name     string
+		input    string
+		opts     []RespOptFunc
+		expResps []rune
+	}{
+		{
+			name:     "line mode, edited, then a second line",
+			input:    "kex\x7fep\rremove\r",
+			opts:     []RespOptFunc{SetLineMode()},
+			expResps: []rune{'k', 'x'},
+		},
+		{
+			name:     "hybrid, edited, then a single key",
+			input:    "rx\x7femove\rk",
+			opts:     []RespOptFunc{SetHybridInput()},
+			expResps: []rune{'x', 'k'},
+		},
+		{
+			name:     "hybrid, first letter edited",
+			input:    "q\x7fremove\r",
+			opts:     []RespOptFunc{SetHybridInput()},
+			expResps: []rune{'x'},
+		},
+	}
+
+	// put the terminal into raw mode before writing so that the input is
+	// passed through unchanged
+	state, err := term.MakeRaw(int(slave.Fd()))
+	if err != nil {
+		t.Fatalf("cannot put the terminal into raw mode: %v", err)
+	}
+	defer term.Restore(int(slave.Fd()), state) //nolint: errcheck
+
+	for _, tc := range testCases {
+		opts := append([]RespOptFunc{
+			SetFile(slave),
+			SetOutput(io.Discard),
+			SetLineEditing(),
+		}, tc.opts...)
+		r := NewOrPanic("test", resps, opts...)
+
+		if _, err = master.Write([]byte(tc.input)); err != nil {
+			t.Fatalf("%s: cannot write to the pseudo-terminal: %v",
+				tc.name, err)
+		}
+
+		for _, exp := range tc.expResps {
+			resCh := make(chan rune, 1)
+			go func() {
+				resp, _, err := r.getResp()
+				if err != nil {
+					t.Errorf("%s: unexpected error: %v", tc.name, err)
+				}
+				resCh <- resp
+			}()
+
+			select {
+			case resp := <-resCh:
+				if resp != exp {
+					t.Errorf("%s: expected %q, got: %q", tc.name, exp, resp)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("%s: timed out waiting for %q", tc.name, exp)
+			}
+		}
+	}
+}
+
+func TestPtyHint(t *testing.T) {
+	master, slave := openPty(t)
+	defer master.Close()
+	defer slave.Close()
+
+	const hint = "(press ? for details)"
+
+	r := NewOrPanic("test",
+		map[rune]string{
+			'y': "yes",
+			'n': "no",
+		},
+		SetFile(slave), SetOutput(slave), SetErrOutput(slave),
+		SetHint(hint))
+
+	// put the terminal into raw mode before writing so that the input is
+	// available to be read without a trailing newline
+	state, err := term.MakeRaw(int(slave.Fd()))
+	if err != nil {
+		t.Fatalf("cannot put the terminal into raw mode: %v", err)
+	}
+	defer term.Restore(int(slave.Fd()), state) //nolint: errcheck
+
+	const endMark = "END"
+
+	outCh := make(chan string, 1)
+	go func() {
+		var b strings.Builder
+		buf := make([]byte, 256)
+		for !strings.HasSuffix(b.String(), endMark) {
+			n, err := master.Read(buf)
+			if err != nil {
+				break
+			}
+			b.WriteString(strings.ReplaceAll(string(buf[:n]), "\r", ""))
+		}
+		outCh <- b.String()
+	}()
+
+	if _, err = master.Write([]byte("zy")); err != nil {
+		t.Fatalf("cannot write to the pseudo-terminal: %v", err)
+	}
+
+	resp, err := r.GetResponse()
+	if resp != 'y' || err != nil {
+		t.Errorf("expected 'y' and no error, got: %q, %v", resp, err)
+	}
+	fmt.Fprint(slave, endMark)
+
+	select {
+	case out := <-outCh:
+		hintIdx := strings.Index(out, hint)
+		errIdx := strings.Index(out, "bad response")
+		if hintIdx < 0 || errIdx < 0 {
+			t.Fatalf("expected the hint and an error in the output: %q", out)
+		}
+		if strings.Count(out, hint) != 1 {
+			t.Errorf("the hint should be shown once: %q", out)
+		}
+		clearIdx := strings.Index(out[hintIdx:], clearBelowSeq)
+		if clearIdx < 0 || hintIdx+clearIdx > errIdx {
+			t.Errorf("the hint was not cleared before the error: %q", out)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the output")
+	}
+}
+
+func TestPtyHybridInputPending(t *testing.T) {
+	master, slave := openPty(t)
+	defer master.Close()
+	defer slave.Close()
+
+	// put the terminal into raw mode before writing so that the input is
+	// available to be read without a trailing newline
+	state, err := term.MakeRaw(int(slave.Fd()))
+	if err != nil {
+		t.Fatalf("cannot put the terminal into raw mode: %v", err)
+	}
+	defer term.Restore(int(slave.Fd()), state) //nolint: errcheck
+
+	r := NewOrPanic("test",
+		map[rune]string{
+			'x': "remove",
+			'k': "keep",
+		},
+		SetFile(slave), SetOutput(io.Discard), SetHybridInput())
+
+	testCases := []struct {
+		name     string
+		input    string
+		expResp  rune
+		expError bool
+	}{
+		{
+			name:     "a lone letter",
+			input:    "r",
+			expResp:  unicode.ReplacementChar,
+			expError: true,
+		},
+		{
+			name:    "a word",
+			input:   "remove\n",
+			expResp: 'x',
+		},
+	}
+
+	for _, tc := range testCases {
+		if _, err = master.Write([]byte(tc.input)); err != nil {
+			t.Fatalf("%s: cannot write to the pseudo-terminal: %v",
+				tc.name, err)
+		}
+
+		type result struct {
+			resp rune
+			err  error
+		}
+		resCh := make(chan result, 1)
+		go func() {
+			resp, _, err := r.getResp()
+			resCh <- result{resp: resp, err: err}
+		}()
+
+		select {
+		case res := <-resCh:
+			if res.resp != tc.expResp {
+				t.Errorf("%s: expected response %q, got: %q",
+					tc.name, tc.expResp, res.resp)
+			}
+			if (res.err != nil) != tc.expError {
+				t.Errorf("%s: unexpected error state: %v", tc.name, res.err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%s: timed out waiting for the response", tc.name)
+		}
+	}
+}
+
+func TestPtyEditorOutput(t *testing.T) {
+	master, slave := openPty(t)
+	defer master.Close()
+	defer slave.Close()
+
+	testCases := []struct {
+		name string
+		out  io.Writer
+		exp  *os.File
+	}{
+		{
+			name: "terminal",
+			out:  slave,
+			exp:  slave,
+		},
+		{
+			name: "terminal, as wrapped by RunPrompts",
+			out:  crlfWrap(slave),
+			exp:  slave,
+		},
+		{
+			name: "not a terminal",
+			out:  io.Discard,
+			exp:  os.Stdout,
+		},
+	}
+
+	for _, tc := range testCases {
+		r := NewOrPanic("test",
+			map[rune]string{
+				'y': "yes",
+				'n': "no",
+			},
+			SetOutput(tc.out))
+
+		if f := r.editorOutput(); f != tc.exp {
+			t.Errorf("%s: expected the editor to write to %s, got: %s",
+				tc.name, tc.exp.Name(), f.Name())
+		}
+	}
+}
+
+func TestPtyEarlyKeyDeadline(t *testing.T) {
+	master, slave := openPty(t)
+	defer master.Close()
+	defer slave.Close()
+
+	// put the terminal into raw mode before writing so that the input is
+	// available to be read without a trailing newline
+	state, err := term.MakeRaw(int(slave.Fd()))
+	if err != nil {
+		t.Fatalf("cannot put the terminal into raw mode: %v", err)
+	}
+	defer term.Restore(int(slave.Fd()), state) //nolint: errcheck
+
+	r := NewOrPanic("test",
+		map[rune]string{
+			'y': "yes",
+			'n': "no",
+		},
+		SetFile(slave), SetOutput(io.Discard),
+		SetMinVisibleTime(time.Hour))
+
+	testCases := []struct {
+		name   string
+		getter func() (rune, error)
+		expErr error
+	}{
+		{
+			name: "deadline",
+			getter: func() (rune, error) {
+				resp, _, err := r.GetResponseDeadline(
+					time.Now().Add(100*time.Millisecond), 0, 0)
+				return resp, err
+			},
+			expErr: ErrTimedOut,
+		},
+		{
+			name: "cancel",
+			getter: func() (rune, error) {
+				done := make(chan struct{})
+				time.AfterFunc(100*time.Millisecond, func() { close(done) })
+				return r.GetResponseWithCancel(done, 0, 0)
+			},
+			expErr: ErrCancelled,
+		},
+	}
+
+	for _, tc := range testCases {
+		// the key arrives before the minimum visible time and so is
+		// discarded; the deadline or cancellation must still be seen
+		if _, err = master.Write([]byte("y")); err != nil {
+			t.Fatalf("%s: cannot write to the pseudo-terminal: %v",
+				tc.name, err)
+		}
+
+		type result struct {
+			resp rune
+			err  error
+		}
+		resCh := make(chan result, 1)
+		go func() {
+			resp, err := tc.getter()
+			resCh <- result{resp: resp, err: err}
+		}()
+
+		select {
+		case res := <-resCh:
+			if !errors.Is(res.err, tc.expErr) {
+				t.Errorf("%s: expected %v, got: %q, %v",
+					tc.name, tc.expErr, res.resp, res.err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%s: timed out waiting for the response", tc.name)
+		}
+	}
+}
+
+func TestPtyEscapeCancels(t *testing.T) {
+	master, slave := openPty(t)
+	defer master.Close()
+	defer slave.Close()
+
+	// put the terminal into raw mode before writing so that the input is
+	// available to be read without a trailing newline
+	state, err := term.MakeRaw(int(slave.Fd()))
+	if err != nil {
+		t.Fatalf("cannot put the terminal into raw mode: %v", err)
+	}
+	defer term.Restore(int(slave.Fd()), state) //nolint: errcheck
+
+	const timeout = 100 * time.Millisecond
+
+	r := NewOrPanic("test",
+		map[rune]string{
+			'y': "yes",
+			'n': "no",
+		},
+		SetFile(slave), SetOutput(io.Discard),
+		SetEscapeCancels(), SetEscapeTimeout(timeout))
+
+	testCases := []struct {
+		name      string
+		input     string
+		expResps  []rune
+		expCancel bool
+	}{
+		{
+			name:      "lone escape",
+			input:     "\x1b",
+			expResps:  []rune{unicode.ReplacementChar},
+			expCancel: true,
+		},
+		{
+			name:     "arrow key in one write, then a valid key",
+			input:    "\x1b[Ay",
+			expResps: []rune{unicode.ReplacementChar, 'y'},
+		},
+	}
+
+	for _, tc := range testCases {
+		if _, err = master.Write([]byte(tc.input)); err != nil {
+			t.Fatalf("%s: cannot write to the pseudo-terminal: %v",
+				tc.name, err)
+		}
+
+		for i, expResp := range tc.expResps {
+			type result struct {
+				resp    rune
+				err     error
+				elapsed time.Duration
+			}
+			resCh := make(chan result, 1)
+			go func() {
+				start := time.Now()
+				resp, _, err := r.GetOnce(0, 0)
+				resCh <- result{
+					resp:    resp,
+					err:     err,
+					elapsed: time.Since(start),
+				}
+			}()
+
+			var res result
+			select {
+			case res = <-resCh:
+			case <-time.After(5 * time.Second):
+				t.Fatalf("%s: timed out waiting for the response", tc.name)
+			}
+
+			if res.resp != expResp {
+				t.Errorf("%s: response %d: expected %q, got: %q",
+					tc.name, i, expResp, res.resp)
+			}
+
+			switch {
+			case expResp != unicode.ReplacementChar:
+				if res.err != nil {
+					t.Errorf("%s: response %d: unexpected error: %v",
+						tc.name, i, res.err)
+				}
+			case tc.expCancel:
+				if !errors.Is(res.err, ErrCancelled) {
+					t.Errorf("%s: expected ErrCancelled, got: %v",
+						tc.name, res.err)
+				}
+				if res.elapsed < timeout {
+					t.Errorf("%s: cancelled after %s,"+
+						" before the escape timeout (%s)",
+						tc.name, res.elapsed, timeout)
+				}
+			default:
+				if res.err == nil || errors.Is(res.err, ErrCancelled) {
+					t.Errorf("%s: expected a bad response error, got: %v",
+						tc.name, res.err)
+				}
+			}
+		}
+	}
+}
+
+func TestPtyRunPromptsCommands(t *testing.T) {
+	if _, err := os.Stat("/bin/sh"); err != nil {
+		t.Skip("there is no shell to run the commands")
+	}
+
+	master, slave := openPty(t)
+	defer master.Close()
+	defer slave.Close()
+
+	// each command records whether the terminal is in canonical (not raw)
+	// mode while it runs
+	dir := t.TempDir()
+	script := func(name, extra string) string {
+		path := dir + "/" + name
+		content := "#!/bin/sh\n" +
+			"if stty -a < " + slave.Name() + " | grep -Eq '(^| )icanon'\n" +
+			"then echo cooked > " + path + ".state\n" +
+			"else echo raw > " + path + ".state\n" +
+			"fi\n" +
+			extra
+		if err := os.WriteFile(path, []byte(content), 0o700); err != nil {
+			t.Fatalf("cannot write the %s script: %v", name, err)
+		}
+		return path
+	}
+	pager := script("pager", "cat > /dev/null\n")
+	editor := script("editor", "echo edited > \"$1\"\n")
+	t.Setenv("VISUAL", editor)
+
+	resps := map[rune]string{
+		'e': "edit",
+		'y': "yes",
+	}
+	var edited string
+	// the same responder is used twice so that both responses are read
+	// through the same buffered reader
+	r := NewOrPanic("test", resps,
+		SetFile(slave), SetOutput(io.Discard), SetErrOutput(io.Discard),
+		SetHelpPager(pager), SetEditorResponse('e', &edited))
+	rs := []*R{r, r}
+
+	before, err := term.GetState(int(slave.Fd()))
+	if err != nil {
+		t.Fatalf("cannot get the terminal state: %v", err)
+	}
+
+	// put the terminal into raw mode before writing so that the input is
+	// available to be read without a trailing newline
+	state, err := term.MakeRaw(int(slave.Fd()))
+	if err != nil {
+		t.Fatalf("cannot put the terminal into raw mode: %v", err)
+	}
+	if _, err = master.Write([]byte("?ey")); err != nil {
+		t.Fatalf("cannot write to the pseudo-terminal: %v", err)
+	}
+	if err = term.Restore(int(slave.Fd()), state); err != nil {
+		t.Fatalf("cannot restore the terminal: %v", err)
+	}
+
+	type result struct {
+		resps []rune
+		err   error
+	}
+	resCh := make(chan result, 1)
+	go func() {
+		got, err := RunPrompts(rs)
+		resCh <- result{resps: got, err: err}
+	}()
+
+	select {
+	case res := <-resCh:
+		if res.err != nil || string(res.resps) != "ey" {
+			t.Errorf("expected \"ey\" and no error, got: %q, %v",
+				string(res.resps), res.err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the responses")
+	}
+
+	for _, name := range []string{"pager", "editor"} {
+		state, err := os.ReadFile(dir + "/" + name + ".state")
+		if err != nil {
+			t.Errorf("the %s did not record the terminal state: %v", name, err)
+			continue
+		}
+		if s := strings.TrimSpace(string(state)); s != "cooked" {
+			t.Errorf("the terminal was %s while the %s ran", s, name)
+		}
+	}
+	if edited != "edited\n" {
+		t.Errorf("unexpected edited text: %q", edited)
+	}
+
+	after, err := term.GetState(int(slave.Fd()))
+	if err != nil {
+		t.Fatalf("cannot get the terminal state: %v", err)
+	}
+	if *before != *after {
+		t.Error("the terminal state was not restored")
+	}
+}
+
+func TestPtyOutputSequences(t *testing.T) {
+	master, slave := openPty(t)
+	defer master.Close()
+	defer slave.Close()
+
+	// put the terminal into raw mode before writing so that the input is
+	// available to be read without a trailing newline
+	state, err := term.MakeRaw(int(slave.Fd()))
+	if err != nil {
+		t.Fatalf("cannot put the terminal into raw mode: %v", err)
+	}
+	defer term.Restore(int(slave.Fd()), state) //nolint: errcheck
+
+	const endMark = "END"
+
+	testCases := []struct {
+		name      string
+		opts      []RespOptFunc
+		expOutput string
+	}{
+		{
+			name: "hide cursor",
+			opts: []RespOptFunc{SetHideCursor()},
+			expOutput: "test? (n/y/?): " +
+				hideCursorSeq + showCursorSeq + endMark,
+		},
+		{
+			name:      "clear after",
+			opts:      []RespOptFunc{SetClearAfter()},
+			expOutput: "test? (n/y/?): " + clearLineSeq + endMark,
+		},
+	}
+
+	for _, tc := range testCases {
+		opts := append([]RespOptFunc{
+			SetFile(slave), SetOutput(slave), SetErrOutput(slave),
+		}, tc.opts...)
+		r := NewOrPanic("test",
+			map[rune]string{
+				'y': "yes",
+				'n': "no",
+			},
+			opts...)
+
+		outCh := make(chan string, 1)
+		go func() {
+			var b strings.Builder
+			buf := make([]byte, 256)
+			for !strings.HasSuffix(b.String(), endMark) {
+				n, err := master.Read(buf)
+				if err != nil {
+					break
+				}
+				b.Write(buf[:n])
+			}
+			outCh <- b.String()
+		}()
+
+		if _, err = master.Write([]byte("y")); err != nil {
+			t.Fatalf("%s: cannot write to the pseudo-terminal: %v",
+				tc.name, err)
+		}
+
+		resp, err := r.GetResponse()
+		if resp != 'y' || err != nil {
+			t.Errorf("%s: expected 'y' and no error, got: %q, %v",
+				tc.name, resp, err)
+		}
+		fmt.Fprint(slave, endMark)
+
+		select {
+		case out := <-outCh:
+			if out != tc.expOutput {
+				t.Errorf("%s: expected output: %q, got: %q",
+					tc.name, tc.expOutput, out)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%s: timed out waiting for the output", tc.name)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package responder
+
+import (
+	"errors"
+	"fmt"
+	"unicode"
+)
+
+// errRepeatPrompt is returned by getResp when the user presses the repeat
+// key (see SetRepeatKey)
+var errRepeatPrompt = errors.New("repeat the prompt")
+
+// SetRepeatKey sets a key which, when pressed, makes the responder show the
+// prompt again. It is not taken as a response or as an error and so does
+// not count against the reprompt limit. This can help users, for instance
+// those using a screen reader, who need the question repeated.
+//
+// The key must not be a valid response, the help rune ('?') or a
+// whitespace character. Unless SetNoFold is given it must also not be the
+// uppercase form of a valid response. If SetNumericHotkeys is given it must
+// not be one of the digits 1 to 9.
+//
+// This applies to GetResponse and the methods which call it, but not to
+// GetOnce or ResponseStream.
+func SetRepeatKey(c rune) RespOptFunc {
+	return func(r *R) error {
+		if _, ok := r.validResps[c]; ok {
+			return fmt.Errorf(
+				"SetRepeatKey: the key (%c) is a valid response", c)
+		}
+		if c == helpRune {
+			return fmt.Errorf(
+				"SetRepeatKey: the key (%c) is used to request help", c)
+		}
+		if unicode.IsSpace(c) {
+			return fmt.Errorf(
+				"SetRepeatKey: the key must not be a whitespace character")
+		}
+
+		r.repeatKey = c
+		r.hasRepeatKey = true
+
+		return nil
+	}
+}
@@ -0,0 +1,102 @@
+package responder
+
+import (
+	"fmt"
+	"unicode"
+)
+
+// ReplayResponder answers the prompts of a responder from a collection of
+// answers keyed by the text of the prompt. It can be used to replay a
+// recorded session deterministically. The prompt text is the prompt passed
+// to New or, if SetPromptFunc has been given, the value returned by the
+// prompt function; it does not include the list of valid responses. Since
+// the prompt function is called each time an answer is needed a single
+// ReplayResponder can answer a sequence of different prompts.
+type ReplayResponder struct {
+	byPrompt map[string]rune
+	r        *R
+}
+
+// NewReplayResponder creates a ReplayResponder which will answer the
+// prompts of the given responder from the map of answers keyed by the
+// prompt text. Each answer is checked against the responder's valid
+// responses and an error is returned if any of them is not valid or is a
+// request for help.
+func NewReplayResponder(
+	r *R,
+	byPrompt map[string]rune,
+) (*ReplayResponder, error) {
+	if r == nil {
+		return nil, fmt.Errorf("the responder must not be nil")
+	}
+
+	rr := &ReplayResponder{
+		byPrompt: make(map[string]rune, len(byPrompt)),
+		r:        r,
+	}
+
+	for prompt, answer := range byPrompt {
+		resp, kind, err := r.Classify(answer)
+		if err != nil {
+			return nil, fmt.Errorf("the answer for %q: %w", prompt, err)
+		}
+		if kind == KindHelp {
+			return nil,
+				fmt.Errorf("the answer for %q: help cannot be requested",
+					prompt)
+		}
+		rr.byPrompt[prompt] = resp
+	}
+
+	return rr, nil
+}
+
+// GetResponse returns the answer for the current prompt text. If there is
+// no answer for the prompt then the default response is returned if one
+// has been set, otherwise an error is returned.
+//
+// If an error is detected the response returned will be the unicode
+// ReplacementChar.
+func (rr ReplayResponder) GetResponse() (rune, error) {
+	prompt := rr.r.promptValue()
+
+	resp, ok := rr.byPrompt[prompt]
+	if ok {
+		return resp, nil
+	}
+
+	dflt, ok, err := rr.r.Default()
+	if err != nil {
+		return unicode.ReplacementChar, err
+	}
+	if ok {
+		return dflt, nil
+	}
+
+	return unicode.ReplacementChar,
+		fmt.Errorf("there is no answer for the prompt %q"+
+			" and there is no default response",
+			prompt)
+}
+
+// GetResponseOrDie calls GetResponse to get the response but if there is an
+// error it will print it and exit with status 1.
+func (rr ReplayResponder) GetResponseOrDie() rune {
+	resp, err := rr.GetResponse()
+	if err != nil {
+		rr.r.reportErrAndExit(err)
+	}
+
+	return resp
+}
+
+// GetResponseIndent returns the answer for the current prompt text. The
+// indents are ignored.
+func (rr ReplayResponder) GetResponseIndent(_, _ int) (rune, error) {
+	return rr.GetResponse()
+}
+
+// GetResponseIndentOrDie calls GetResponseOrDie. The indents are ignored.
+func (rr ReplayResponder) GetResponseIndentOrDie(_, _ int) rune {
+	return rr.GetResponseOrDie()
+}
@@ -2,13 +2,15 @@ package responder
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"sort"
 	"strings"
-	"syscall"
+	"time"
 	"unicode"
+	"unicode/utf8"
 
 	"github.com/nickwells/twrap.mod/twrap"
 	"golang.org/x/term"
@@ -25,30 +27,182 @@ type Responder interface {
 const (
 	helpRune      = '?'
 	errExitStatus = 1
+
+	dfltHelpSuffix = " (this is the default)"
+
+	terminalPunct = "?:!."
+
+	noFD = -1
+
+	tersePrompt = "> "
+
+	autoIndentDivisor = 20
+	autoIndentMax     = 8
+
+	hideCursorSeq = "\x1b[?25l"
+	showCursorSeq = "\x1b[?25h"
+	clearLineSeq  = "\r\x1b[2K"
+
+	cursorUpSeq    = "\x1b[1A"
+	cursorRightFmt = "\x1b[%dC"
+	clearBelowSeq  = "\x1b[J"
 )
 
+// respState holds the state of the responder which changes as responses are
+// read
+type respState struct {
+	lastErr   error
+	lineTerm  *term.Terminal
+	lineEd    *lineEditor
+	helpShown bool
+	lineEnded bool
+}
+
 // R holds the details needed to collect and validate a response
 type R struct {
-	prompt string
+	prompt     string
+	promptFunc func() string
+	promptTmpl string
+	id         string
 
 	validResps map[rune]string
+	descFunc   func(rune) string
 	hasDflt    bool
 	dflt       rune
+	dfltFunc   func() (rune, bool)
+
+	interDflt       rune
+	hasInterDflt    bool
+	nonInterDflt    rune
+	hasNonInterDflt bool
+
+	dfltHelpSuffix  string
+	dfltMarker      string
+	forbiddenDflts  map[rune]bool
+	hasDfltMarker   bool
+	showDfltKey     bool
+	noParens        bool
+	noTrailingSpace bool
+	respColors      map[rune]string
 
 	maxReprompts int
 	limitPrompts bool
+	errGrace     int
+
+	helpCountsAsReprompt bool
+
+	fd          int
+	input       io.Reader
+	rdr         *bufio.Reader
+	rdrBufSize  int
+	captureRaw  *[]byte
+	byteMode    bool
+	lineMode    bool
+	hybridInput bool
+	lineEditing bool
+	noTrimInput bool
+	makeRaw     func(fd int) (restore func() error, err error)
+	rawSess     *rawSession
+
+	escCancels bool
+	escTimeout time.Duration
+
+	fnKeys    map[int]rune
+	fnKeySeqs map[int]string
+
+	deadline time.Time
+	done     <-chan struct{}
+
+	minVisible     time.Duration
+	beepOnEarlyKey bool
+	promptTime     time.Time
+
+	out        io.Writer
+	errOut     io.Writer
+	transcript io.Writer
+
+	metricsFunc func(Metrics)
+
+	hideCursor   bool
+	confirmEcho  bool
+	echoRejected bool
+	smartSuffix  bool
+	noFold       bool
+	autoHideHelp bool
+	noHelp       bool
+	tersePrompt  bool
+	helpPager    []string
+
+	ignoreBackspace bool
+	dedupeErrors    bool
+	consumeLine     bool
+	tabCyclesDflt   bool
+
+	requireHelpFirst bool
+
+	leadingBlankLine bool
+	hint             string
+	clearAfter       bool
+
+	numericHotkeys bool
+
+	editResp    rune
+	hasEditResp bool
+	editResult  *string
 
-	fd  int
-	rdr *bufio.Reader
+	abortResp    rune
+	hasAbortResp bool
+
+	repeatKey    rune
+	hasRepeatKey bool
 
 	indent      int
 	indentFirst int
+	hasIndents  bool
+	autoIndent  bool
+
+	dieMsg func(err error) string
+
+	compiled *compiledResps
+
+	autoSelectSingle bool
+	autoSelectNotice bool
+
+	columnar bool
+
+	state *respState
 }
 
 // RespOptFunc is a function which can be passed to the New function
 // to set optional parts of the R
 type RespOptFunc func(*R) error
 
+// SetPromptFunc sets a function which will be called to generate the
+// prompt each time that it is printed. This allows the prompt to reflect
+// some changing state, for instance "File 3 of 10: delete". If this is set
+// then the prompt passed to New is not used.
+func SetPromptFunc(fn func() string) RespOptFunc {
+	return func(r *R) error {
+		if fn == nil {
+			return fmt.Errorf("SetPromptFunc: the function must not be nil")
+		}
+
+		r.promptFunc = fn
+
+		return nil
+	}
+}
+
+// SetID sets an identifier for the responder. This is used by a
+// FileAnswerer to find the answer for the responder.
+func SetID(id string) RespOptFunc {
+	return func(r *R) error {
+		r.id = id
+
+		return nil
+	}
+}
+
 // SetDefault sets the default value for a Responder
 func SetDefault(d rune) RespOptFunc {
 	return func(r *R) error {
@@ -66,9 +220,122 @@ func SetDefault(d rune) RespOptFunc {
 	}
 }
 
+// SetDefaultFunc sets a function which will be called to find the default
+// response each time the user is prompted. The function should return the
+// default response and true, or false if there is no default this time.
+// The default returned must be in the list of valid responses; this can
+// only be checked when the function is called and so an error will be
+// returned then. If this is set then any default given by SetDefault is
+// not used.
+func SetDefaultFunc(fn func() (rune, bool)) RespOptFunc {
+	return func(r *R) error {
+		if fn == nil {
+			return fmt.Errorf("SetDefaultFunc: the function must not be nil")
+		}
+
+		r.dfltFunc = fn
+
+		return nil
+	}
+}
+
+// SetDescriptionFunc sets a function which will be called to get the
+// description of a response when it is needed, for instance when the help
+// message is shown. This allows the descriptions to be generated on demand,
+// for instance from a translation catalogue. If the function returns an
+// empty string then the description given when the responder was created
+// is used. The set of valid responses is still given by the responses
+// passed to New.
+func SetDescriptionFunc(fn func(rune) string) RespOptFunc {
+	return func(r *R) error {
+		if fn == nil {
+			return fmt.Errorf("SetDescriptionFunc: the function must not be nil")
+		}
+
+		r.descFunc = fn
+
+		return nil
+	}
+}
+
+// description returns the description of the response, calling the
+// description function if there is one
+func (r R) description(c rune) string {
+	if r.descFunc != nil {
+		if desc := r.descFunc(c); desc != "" {
+			return desc
+		}
+	}
+
+	return r.validResps[c]
+}
+
+// SetDefaultHelpSuffix sets the text that is appended to the description of
+// the default response when the help message is printed. The default value
+// is " (this is the default)".
+func SetDefaultHelpSuffix(s string) RespOptFunc {
+	return func(r *R) error {
+		r.dfltHelpSuffix = s
+
+		return nil
+	}
+}
+
+// SetDefaultHelpMarker sets a marker, such as "* ", which is shown before
+// the default response in the help message in place of the default help
+// suffix (see SetDefaultHelpSuffix). The other responses are indented by
+// the width of the marker so that they stay aligned. The marker must not be
+// empty.
+func SetDefaultHelpMarker(marker string) RespOptFunc {
+	return func(r *R) error {
+		if marker == "" {
+			return fmt.Errorf("SetDefaultHelpMarker: the marker must not be empty")
+		}
+
+		r.dfltMarker = marker
+		r.hasDfltMarker = true
+
+		return nil
+	}
+}
+
+// SetShowDefaultKey makes the list of valid responses show the default as
+// "Enter=y" rather than "[y]". This makes it clearer to novice users how the
+// default can be chosen. Note that any whitespace character will still
+// select the default, not just the Enter key.
+func SetShowDefaultKey() RespOptFunc {
+	return func(r *R) error {
+		r.showDfltKey = true
+
+		return nil
+	}
+}
+
+// SetNoParens makes the list of valid responses be shown without the
+// surrounding parentheses, for instance "y/n/?: " rather than "(y/n/?): ".
+func SetNoParens() RespOptFunc {
+	return func(r *R) error {
+		r.noParens = true
+
+		return nil
+	}
+}
+
+// SetNoTrailingSpace makes the list of valid responses end with a colon
+// rather than a colon and a space, so that the cursor is left immediately
+// after the colon. By default the space is shown.
+func SetNoTrailingSpace() RespOptFunc {
+	return func(r *R) error {
+		r.noTrailingSpace = true
+
+		return nil
+	}
+}
+
 // SetMaxReprompts sets the maximum number of times that the user
 // will be reprompted for a valid response before reporting an error. The
-// value must be greater than 0
+// value must be greater than 0. Requests for help are not counted unless
+// SetHelpCountsAsReprompt(true) is given.
 func SetMaxReprompts(max int) RespOptFunc {
 	return func(r *R) error {
 		if max <= 0 {
@@ -85,6 +352,350 @@ func SetMaxReprompts(max int) RespOptFunc {
 	}
 }
 
+// SetHelpCountsAsReprompt controls whether a request for help counts as
+// one of the reprompts allowed by SetMaxReprompts. By default it does not
+// and so the user can ask for help as often as they like without using up
+// their reprompts. If this is set to true and the user asks for help once
+// too often then an error is returned.
+func SetHelpCountsAsReprompt(b bool) RespOptFunc {
+	return func(r *R) error {
+		r.helpCountsAsReprompt = b
+
+		return nil
+	}
+}
+
+// SetByteMode makes the responder read a single byte rather than a UTF-8
+// encoded rune. All the responses must be single-byte (ASCII) characters;
+// responses which are not will cause New to return an error. A key which is
+// not ASCII, such as 'é', is read as a single key and rejected.
+//
+// This is useful for strict terminal protocols or device consoles where a
+// multi-byte sequence would not be expected.
+func SetByteMode() RespOptFunc {
+	return func(r *R) error {
+		r.byteMode = true
+
+		return nil
+	}
+}
+
+// SetHideCursor makes the responder hide the cursor while it is waiting for
+// the user to respond. The cursor is shown again once the response has been
+// read, whether or not the read succeeded. The cursor is only hidden if the
+// output is a terminal.
+func SetHideCursor() RespOptFunc {
+	return func(r *R) error {
+		r.hideCursor = true
+
+		return nil
+	}
+}
+
+// SetConfirmEcho makes the responder print the description of the chosen
+// response on a new line once a valid response has been read. This is also
+// done if the default response is chosen by entering whitespace.
+func SetConfirmEcho() RespOptFunc {
+	return func(r *R) error {
+		r.confirmEcho = true
+
+		return nil
+	}
+}
+
+// SetEchoRejected makes the responder echo any printable key which is not a
+// valid response, where it was typed, before the error is reported so that
+// the user can see what they pressed. If the output is a terminal the key
+// is shown in red. The echoed key ends the prompt line so the error and
+// the next prompt start on a new line even if errors are written to a
+// different output (see SetErrOutput).
+func SetEchoRejected() RespOptFunc {
+	return func(r *R) error {
+		r.echoRejected = true
+
+		return nil
+	}
+}
+
+// SetSmartSuffix makes the responder omit the "? " that is normally
+// printed after the prompt if the prompt already ends with punctuation (one
+// of "?:!."). Any trailing whitespace in the prompt is ignored and a single
+// space is printed instead. This avoids prompts like "Continue?? ".
+func SetSmartSuffix() RespOptFunc {
+	return func(r *R) error {
+		r.smartSuffix = true
+
+		return nil
+	}
+}
+
+// SetAbortResponse sets a response which, when chosen, will cause the
+// response to be returned along with the ErrAborted error. This lets the
+// caller distinguish a request to abort the whole operation (using
+// errors.Is) without needing to check the response. The response must be
+// in the list of valid responses.
+func SetAbortResponse(c rune) RespOptFunc {
+	return func(r *R) error {
+		if _, ok := r.validResps[c]; !ok {
+			return fmt.Errorf(
+				"SetAbortResponse: the response (%c) is not"+
+					" in the list of valid responses",
+				c)
+		}
+
+		r.abortResp = c
+		r.hasAbortResp = true
+
+		return nil
+	}
+}
+
+// SetInput sets the reader from which the responses are read. The default
+// is standard input. If the reader is an *os.File then its file descriptor
+// is used to put the terminal into raw mode, otherwise no attempt is made to
+// do so. This is mostly useful for testing.
+func SetInput(rdr io.Reader) RespOptFunc {
+	return func(r *R) error {
+		if rdr == nil {
+			return fmt.Errorf("SetInput: the reader must not be nil")
+		}
+
+		r.fd = noFD
+		if f, ok := rdr.(*os.File); ok {
+			r.fd = int(f.Fd())
+		}
+		r.input = rdr
+		r.rdr = r.newReader(rdr)
+
+		return nil
+	}
+}
+
+// SetReaderBufferSize sets the size of the buffer used when reading the
+// responses. The default is the default size used by the bufio package.
+// The size applies to the reader set by SetInput or SetFile regardless of
+// the order in which the options are given. The size must be positive.
+func SetReaderBufferSize(n int) RespOptFunc {
+	return func(r *R) error {
+		if n <= 0 {
+			return fmt.Errorf(
+				"SetReaderBufferSize: the size (%d) must be greater than 0",
+				n)
+		}
+
+		r.rdrBufSize = n
+		r.rdr = r.newReader(r.input)
+
+		return nil
+	}
+}
+
+// newReader returns a buffered reader for the input using the buffer size
+// if one has been set. If the raw input is being captured (see
+// SetCaptureRaw) the input is read through a captureReader.
+func (r R) newReader(rdr io.Reader) *bufio.Reader {
+	if r.captureRaw != nil {
+		rdr = captureReader{rdr: rdr, buf: r.captureRaw}
+	}
+
+	if r.rdrBufSize > 0 {
+		return bufio.NewReaderSize(rdr, r.rdrBufSize)
+	}
+
+	return bufio.NewReader(rdr)
+}
+
+// SetFile sets the file from which the responses are read. The file's
+// descriptor is used to put the terminal into raw mode. This allows the
+// responder to be used with a terminal other than standard input, for
+// instance a pseudo-terminal in an automated test.
+func SetFile(f *os.File) RespOptFunc {
+	return func(r *R) error {
+		if f == nil {
+			return fmt.Errorf("SetFile: the file must not be nil")
+		}
+
+		r.fd = int(f.Fd())
+		r.input = f
+		r.rdr = r.newReader(f)
+
+		return nil
+	}
+}
+
+// SetRawModeFuncs sets the function used to put the terminal into raw mode
+// before reading a response. It should return a function which will
+// restore the terminal to its previous state. The default uses the
+// MakeRaw and Restore functions from the golang.org/x/term package. If the
+// function returns an error the response is read without changing the
+// terminal mode.
+//
+// This allows an alternative terminal implementation to be used or the raw
+// mode handling to be tested.
+func SetRawModeFuncs(
+	makeRaw func(fd int) (restore func() error, err error),
+) RespOptFunc {
+	return func(r *R) error {
+		if makeRaw == nil {
+			return fmt.Errorf("SetRawModeFuncs: the function must not be nil")
+		}
+
+		r.makeRaw = makeRaw
+
+		return nil
+	}
+}
+
+// SetOutput sets the writer to which the prompt and the help message are
+// written. The default is standard output; a common alternative is to write
+// the prompt to standard error so that standard output can be piped
+// elsewhere. The responses are still read from standard input.
+func SetOutput(w io.Writer) RespOptFunc {
+	return func(r *R) error {
+		if w == nil {
+			return fmt.Errorf("SetOutput: the writer must not be nil")
+		}
+
+		r.out = w
+
+		return nil
+	}
+}
+
+// SetErrOutput sets the writer to which the errors reported after an
+// invalid response, and by the OrDie methods before they exit, are
+// written. The default is standard error.
+func SetErrOutput(w io.Writer) RespOptFunc {
+	return func(r *R) error {
+		if w == nil {
+			return fmt.Errorf("SetErrOutput: the writer must not be nil")
+		}
+
+		r.errOut = w
+
+		return nil
+	}
+}
+
+// SetNoFold stops the responder from mapping the rune read to lowercase
+// before checking it against the valid responses; the rune must match a
+// response exactly as typed. Since New only allows lowercase responses, an
+// uppercase rune will always be rejected as an invalid response.
+func SetNoFold() RespOptFunc {
+	return func(r *R) error {
+		r.noFold = true
+
+		return nil
+	}
+}
+
+// SetAutoHideHelp makes the responder disable help if all of the
+// descriptions of the responses are empty. The help rune is then not shown
+// in the list of valid responses and is treated as an invalid response.
+// Help is still available if any description is not empty.
+func SetAutoHideHelp() RespOptFunc {
+	return func(r *R) error {
+		r.autoHideHelp = true
+
+		return nil
+	}
+}
+
+// SetNoHelp disables help. The help rune is then not shown in the list of
+// valid responses and is treated as an invalid response.
+func SetNoHelp() RespOptFunc {
+	return func(r *R) error {
+		r.noHelp = true
+
+		return nil
+	}
+}
+
+// SetTersePrompt makes the responder print the full prompt only the first
+// time it asks for a response. If the user gives an invalid response then
+// only a short prompt ("> ") is shown when asking again. The full prompt is
+// shown again after the help message has been printed.
+func SetTersePrompt() RespOptFunc {
+	return func(r *R) error {
+		r.tersePrompt = true
+
+		return nil
+	}
+}
+
+// SetLeadingBlankLine makes the responder print a blank line before the
+// prompt is first shown. This separates the prompt from any preceding
+// output. The blank line is not printed when the user is asked again.
+func SetLeadingBlankLine() RespOptFunc {
+	return func(r *R) error {
+		r.leadingBlankLine = true
+
+		return nil
+	}
+}
+
+// SetClearAfter makes the responder clear the line with the prompt once a
+// valid response has been read, leaving no trace of the prompt. This is
+// only done if the output is a terminal. Note that only the current line is
+// cleared and so any earlier lines (for instance, from the help message or
+// a prompt that has wrapped) are left.
+func SetClearAfter() RespOptFunc {
+	return func(r *R) error {
+		r.clearAfter = true
+
+		return nil
+	}
+}
+
+// SetIgnoreBackspace makes the responder ignore the Backspace key (either
+// of the characters BS or DEL) rather than report it as an invalid
+// response. The responder simply waits for another key to be pressed.
+func SetIgnoreBackspace() RespOptFunc {
+	return func(r *R) error {
+		r.ignoreBackspace = true
+
+		return nil
+	}
+}
+
+// SetErrorGrace sets the number of invalid responses for which no error
+// message is shown; the user is simply asked again. Only subsequent invalid
+// responses will have an error message shown. This smooths over stray
+// keypresses. Note that these invalid responses still count towards the
+// maximum number of reprompts (see SetMaxReprompts). The value must not be
+// negative.
+func SetErrorGrace(n int) RespOptFunc {
+	return func(r *R) error {
+		if n < 0 {
+			return fmt.Errorf(
+				"SetErrorGrace: the number of invalid responses (%d) must be"+
+					" greater than or equal to 0",
+				n)
+		}
+
+		r.errGrace = n
+
+		return nil
+	}
+}
+
+// SetDieMessage sets a function which will be used to format the message
+// printed to the error output (see SetErrOutput) by the OrDie methods
+// before they exit. The message is printed followed by a newline. If this
+// is not set then the error is printed on a new line, indented by four more
+// spaces than the responder's indent.
+func SetDieMessage(fn func(err error) string) RespOptFunc {
+	return func(r *R) error {
+		if fn == nil {
+			return fmt.Errorf("SetDieMessage: the function must not be nil")
+		}
+
+		r.dieMsg = fn
+
+		return nil
+	}
+}
+
 // SetIndents sets the indents for the first and subsequent lines of output
 func SetIndents(indentFirst, indent int) RespOptFunc {
 	return func(r *R) error {
@@ -103,11 +714,59 @@ func SetIndents(indentFirst, indent int) RespOptFunc {
 
 		r.indent = indent
 		r.indentFirst = indentFirst
+		r.hasIndents = true
+
+		return nil
+	}
+}
+
+// SetAutoIndent sets the indents from the width of the terminal that the
+// output is written to; wider terminals get a larger indent, up to a
+// maximum. The width is measured when the responder is created. If the
+// output is not a terminal the indents are left at zero. If SetIndents is
+// also given then the explicit indents are used, regardless of the order
+// in which the options are given.
+func SetAutoIndent() RespOptFunc {
+	return func(r *R) error {
+		r.autoIndent = true
 
 		return nil
 	}
 }
 
+// autoIndentSize returns the indent to use for a terminal of the given
+// width
+func autoIndentSize(width int) int {
+	indent := width / autoIndentDivisor
+	if indent > autoIndentMax {
+		indent = autoIndentMax
+	}
+
+	return indent
+}
+
+// setAutoIndents sets the indents from the width of the output terminal if
+// SetAutoIndent has been given and the indents have not been set
+// explicitly
+func (r *R) setAutoIndents() {
+	if !r.autoIndent || r.hasIndents {
+		return
+	}
+
+	f, ok := r.out.(interface{ Fd() uintptr })
+	if !ok {
+		return
+	}
+
+	width, _, err := term.GetSize(int(f.Fd()))
+	if err != nil {
+		return
+	}
+
+	r.indentFirst = autoIndentSize(width)
+	r.indent = r.indentFirst
+}
+
 // NewOrPanic creates a new responder and panics if there are any errors
 func NewOrPanic(
 	prompt string,
@@ -121,69 +780,354 @@ func NewOrPanic(
 	return r
 }
 
-// New creates a responder and verifies that it is correct
+// New creates a responder and verifies that it is correct.
+//
+// The responses must be lowercase, must not be whitespace and must not be
+// the help rune ('?'). They must also be printable unless SetByteMode is
+// given, in which case control characters are allowed. Digits are allowed
+// as responses but the digits 1 to 9 cannot then be used as numeric hotkeys
+// (see SetNumericHotkeys). The descriptions of the responses may contain
+// any printable characters and spaces but must not contain control
+// characters such as newlines or tabs as these would break the formatting
+// of the help message.
 func New(
 	prompt string,
 	responses map[rune]string,
 	opts ...RespOptFunc,
 ) (*R, error) {
-	r := &R{
-		prompt: prompt,
-		fd:     syscall.Stdin,
-		rdr:    bufio.NewReader(os.Stdin),
+	r := newR(prompt)
+
+	if err := ValidateResponses(responses, helpRune); err != nil {
+		return nil, err
 	}
 
-	if len(responses) <= 1 {
-		return nil,
-			fmt.Errorf("too few allowed responses - there must be at least 2")
+	r.validResps = responses
+
+	for _, o := range opts {
+		err := o(r)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := r.checkOptions(); err != nil {
+		return nil, err
+	}
+
+	r.setAutoIndents()
+
+	return r, nil
+}
+
+// newR returns a new R with the default settings
+func newR(prompt string) *R {
+	return &R{
+		prompt:         prompt,
+		dfltHelpSuffix: dfltHelpSuffix,
+		fd:             int(os.Stdin.Fd()),
+		input:          os.Stdin,
+		rdr:            bufio.NewReader(os.Stdin),
+		out:            os.Stdout,
+		errOut:         os.Stderr,
+		escTimeout:     dfltEscapeTimeout,
+		makeRaw:        termMakeRaw,
+		state:          &respState{},
 	}
+}
 
-	for v := range responses {
-		if unicode.IsUpper(v) {
-			return nil,
-				fmt.Errorf(
-					"only lowercase responses are allowed - '%c' is uppercase",
+// checkOptions checks that the options which have been applied are
+// consistent with each other and with the responses. The options are
+// applied in order and so an option cannot check against an option that
+// is applied later; this is called once all the options have been applied
+// to perform any such cross-checks.
+func (r R) checkOptions() error {
+	if err := r.checkForbiddenDefaults(); err != nil {
+		return err
+	}
+
+	if r.hasRepeatKey && !r.noFold {
+		if _, ok := r.validResps[unicode.ToLower(r.repeatKey)]; ok {
+			return fmt.Errorf(
+				"SetRepeatKey: the key (%c) is the uppercase form"+
+					" of a valid response",
+				r.repeatKey)
+		}
+	}
+
+	if r.hasRepeatKey && r.numericHotkeys &&
+		r.repeatKey >= firstHotkey && r.repeatKey <= lastHotkey {
+		return fmt.Errorf(
+			"SetRepeatKey: the key (%c) is used as a numeric hotkey",
+			r.repeatKey)
+	}
+
+	if r.requireHelpFirst && r.noHelp {
+		return fmt.Errorf(
+			"SetRequireHelpFirst and SetNoHelp cannot both be used")
+	}
+
+	if len(r.helpPager) > 0 && r.noHelp {
+		return fmt.Errorf(
+			"SetHelpPager and SetNoHelp cannot both be used")
+	}
+
+	if r.lineMode && r.tabCyclesDflt {
+		return fmt.Errorf(
+			"SetLineMode and SetTabCyclesDefault cannot both be used")
+	}
+
+	if r.lineMode && r.escCancels {
+		return fmt.Errorf(
+			"SetLineMode and SetEscapeCancels cannot both be used")
+	}
+
+	if r.byteMode && r.lineMode {
+		return fmt.Errorf(
+			"SetByteMode and SetLineMode cannot both be used")
+	}
+
+	if r.byteMode {
+		for v := range r.validResps {
+			if v >= utf8.RuneSelf {
+				return fmt.Errorf(
+					"SetByteMode: the response '%c' is not"+
+						" a single-byte character",
 					v)
+			}
 		}
-		if unicode.IsSpace(v) {
-			return nil,
-				fmt.Errorf(
-					"a whitespace character is not an allowed response" +
-						" - it is used to select the default response")
+	} else {
+		for v := range r.validResps {
+			if !unicode.IsPrint(v) {
+				return fmt.Errorf(
+					"the response %U is not a printable character"+
+						" - this is only allowed with SetByteMode",
+					v)
+			}
+		}
+	}
+
+	return nil
+}
+
+// ValidateResponses checks the responses using the same rules as New. This
+// allows a map of responses, perhaps assembled from several sources, to be
+// checked before it is used. There must be at least two responses, each
+// response must be lowercase and must not be whitespace or the help rune,
+// and the descriptions must not contain control characters. The responder
+// always uses '?' to request help; pass that as the help rune to apply
+// exactly the checks made by New.
+//
+// Note that New also checks that the responses are printable characters,
+// unless SetByteMode is given; that check depends on the options and so
+// it is not made here.
+func ValidateResponses(responses map[rune]string, help rune) error {
+	if len(responses) <= 1 {
+		return fmt.Errorf(
+			"too few allowed responses - there must be at least 2")
+	}
+
+	for v, desc := range responses {
+		if err := checkResponse(v, desc, help); err != nil {
+			return err
 		}
-		if v == helpRune {
-			return nil,
-				fmt.Errorf(
-					"'%c' is not an allowed response"+
-						" - it is used to request help",
-					helpRune)
+	}
+
+	return nil
+}
+
+// checkResponse checks that a single response and its description are
+// valid. The response must not be the help rune.
+func checkResponse(v rune, desc string, help rune) error {
+	if unicode.IsUpper(v) {
+		return fmt.Errorf(
+			"only lowercase responses are allowed - '%c' is uppercase",
+			v)
+	}
+	if unicode.IsSpace(v) {
+		return fmt.Errorf(
+			"a whitespace character is not an allowed response" +
+				" - it is used to select the default response")
+	}
+	if v == help {
+		return fmt.Errorf(
+			"'%c' is not an allowed response"+
+				" - it is used to request help",
+			help)
+	}
+
+	return checkDesc(v, desc)
+}
+
+// Validate checks that the responder is still well-formed. It performs the
+// same checks on the responses as New and also checks that any responses
+// given to the options (such as the default) are still valid responses and
+// that the options are consistent with each other.
+func (r R) Validate() error {
+	if err := ValidateResponses(r.validResps, helpRune); err != nil {
+		return err
+	}
+
+	if r.hasDflt {
+		if _, ok := r.validResps[r.dflt]; !ok {
+			return fmt.Errorf(
+				"the default response (%c) is not"+
+					" in the list of valid responses",
+				r.dflt)
 		}
 	}
 
-	r.validResps = responses
+	if r.hasEditResp {
+		if _, ok := r.validResps[r.editResp]; !ok {
+			return fmt.Errorf(
+				"the editor response (%c) is not"+
+					" in the list of valid responses",
+				r.editResp)
+		}
+	}
+
+	if r.hasAbortResp {
+		if _, ok := r.validResps[r.abortResp]; !ok {
+			return fmt.Errorf(
+				"the abort response (%c) is not"+
+					" in the list of valid responses",
+				r.abortResp)
+		}
+	}
+
+	if r.numericHotkeys {
+		for v := range r.validResps {
+			if v >= firstHotkey && v <= lastHotkey {
+				return fmt.Errorf(
+					"the response '%c' is also used as a numeric hotkey",
+					v)
+			}
+		}
+	}
+
+	return r.checkOptions()
+}
+
+// checkDesc checks that the description of the response does not contain
+// any control characters. It returns an error naming the response if it
+// does.
+func checkDesc(v rune, desc string) error {
+	for _, c := range desc {
+		if unicode.IsControl(c) {
+			return fmt.Errorf(
+				"the description of '%c' contains a control character (%U)"+
+					" - only printable characters and spaces are allowed",
+				v, c)
+		}
+	}
+
+	return nil
+}
+
+// resolveDefault returns a copy of the responder with the default set from
+// the default function, if there is one. The function is cleared in the
+// copy so that it is called only once. If the function returns a default
+// which is not a valid response then the copy has no default and an error is
+// returned. If there is no default function then any interactive or
+// non-interactive default is used.
+func (r R) resolveDefault() (R, error) {
+	if r.dfltFunc == nil {
+		return r.resolveModeDefault(), nil
+	}
+
+	d, ok := r.dfltFunc()
+	r.dfltFunc = nil
+	r.hasDflt = false
+
+	if !ok {
+		return r, nil
+	}
+
+	if _, valid := r.validResps[d]; !valid {
+		return r, fmt.Errorf(
+			"the default response (%c) given by the default function"+
+				" is not in the list of valid responses",
+			d)
+	}
+	if r.forbiddenDflts[d] {
+		return r, fmt.Errorf("the default function: %w",
+			forbiddenDefaultErr(d))
+	}
+
+	r.dflt = d
+	r.hasDflt = true
+
+	return r, nil
+}
+
+// Default returns the default response and true if there is a default,
+// otherwise it returns false. If a default function has been set (see
+// SetDefaultFunc) then it is called to find the default and an error is
+// returned if the default it gives is not valid.
+func (r R) Default() (rune, bool, error) {
+	r, err := r.resolveDefault()
+	if err != nil {
+		return unicode.ReplacementChar, false, err
+	}
+
+	return r.dflt, r.hasDflt, nil
+}
+
+// PrintValidResponses prints the valid response runes separated
+// by a slash.
+//
+// A rune matching the default is shown in brackets (like so: [y]) or, if
+// SetShowDefaultKey has been given, like so: Enter=y.
+func (r R) PrintValidResponses() {
+	fmt.Fprint(r.out, r.ValidResponsesString())
+}
+
+// ValidResponsesString returns the string that PrintValidResponses prints.
+// If the responses are being shown in columns (see SetColumnarPrompt) then
+// this is empty and the prompt ends with the suffix (see SetSmartSuffix).
+func (r R) ValidResponsesString() string {
+	r, _ = r.resolveDefault()
+
+	if r.useColumns() {
+		return ""
+	}
+
+	tail := ": "
+	if r.noTrailingSpace {
+		tail = ":"
+	}
+
+	if r.noParens {
+		return r.responsesList() + tail
+	}
+
+	return "(" + r.responsesList() + ")" + tail
+}
 
-	for _, o := range opts {
-		err := o(r)
-		if err != nil {
-			return nil, err
-		}
+// responsesList returns the valid responses separated by slashes, using
+// the compiled list if possible (see Compile). The responder's default
+// should already have been resolved.
+func (r R) responsesList() string {
+	if list, ok := r.compiledList(); ok {
+		return list
 	}
 
-	return r, nil
+	return r.buildResponsesList()
 }
 
-// PrintValidResponses prints the valid response runes separated
-// by a slash.
-//
-// A rune matching the default is shown in brackets (like so: [y]).
-func (r R) PrintValidResponses() {
-	fmt.Print("(")
+// buildResponsesList builds the list of valid responses separated by
+// slashes. The responder's default should already have been resolved.
+func (r R) buildResponsesList() string {
+	var b strings.Builder
 
 	responses := r.getSortedValidResponses()
 
 	sep := ""
 	if r.hasDflt {
-		fmt.Printf("[%c]", r.dflt)
+		if r.showDfltKey {
+			fmt.Fprintf(&b, "Enter=%s", r.promptKey(r.dflt))
+		} else {
+			fmt.Fprintf(&b, "[%s]", r.promptKey(r.dflt))
+		}
 		sep = "/"
 	}
 	for _, c := range responses {
@@ -191,18 +1135,92 @@ func (r R) PrintValidResponses() {
 			continue
 		}
 
-		fmt.Printf("%s%c", sep, c)
+		fmt.Fprintf(&b, "%s%s", sep, r.promptKey(c))
 		sep = "/"
 	}
-	fmt.Printf("%s%c): ", sep, helpRune)
+	if r.helpEnabled() {
+		fmt.Fprintf(&b, "%s%c", sep, helpRune)
+	}
+
+	return b.String()
 }
 
 // PrintPrompt prints the prompt and any valid responses.
 func (r R) PrintPrompt() {
-	fmt.Print(r.prompt)
-	fmt.Print("? ")
+	fmt.Fprint(r.out, r.PromptString())
+}
+
+// PromptString returns the string that PrintPrompt prints
+func (r R) PromptString() string {
+	if r.promptTmpl != "" {
+		return r.renderPromptTmpl()
+	}
+
+	return r.promptText() + r.ValidResponsesString()
+}
+
+// PromptWidth returns the width of the string that PrintPrompt prints. The
+// width is the number of columns that the prompt takes on a terminal, not
+// the number of bytes or runes: East Asian wide characters (such as many
+// Chinese, Japanese and Korean characters) and most emoji count as two
+// columns and combining characters (such as an accent following a letter)
+// count as none. Any colours set with SetResponseColor are not included in
+// the width.
+func (r R) PromptWidth() int {
+	r.respColors = nil
+	r.compiled = nil
+
+	return displayWidth(r.PromptString())
+}
+
+// promptText returns the prompt followed by the suffix
+func (r R) promptText() string {
+	prompt := r.promptValue()
+
+	if r.smartSuffix {
+		trimmed := strings.TrimRightFunc(prompt, unicode.IsSpace)
+		if strings.ContainsAny(lastRune(trimmed), terminalPunct) {
+			return trimmed + " "
+		}
+	}
+
+	return prompt + "? "
+}
+
+// promptValue returns the prompt, calling the prompt function if there is
+// one
+func (r R) promptValue() string {
+	if r.promptFunc != nil {
+		return r.promptFunc()
+	}
+
+	return r.prompt
+}
+
+// lastRune returns the last rune in the string as a string. It returns the
+// empty string if the string is empty.
+func lastRune(s string) string {
+	_, size := utf8.DecodeLastRuneInString(s)
+	return s[len(s)-size:]
+}
+
+// helpEnabled reports whether the user can request help
+func (r R) helpEnabled() bool {
+	if r.noHelp {
+		return false
+	}
+
+	if !r.autoHideHelp {
+		return true
+	}
+
+	for k := range r.validResps {
+		if r.description(k) != "" {
+			return true
+		}
+	}
 
-	r.PrintValidResponses()
+	return false
 }
 
 // getSortedValidResponses gets the valid responses in lexicographic order
@@ -220,6 +1238,28 @@ func (r R) getSortedValidResponses() []rune {
 	return keys
 }
 
+// Response holds a valid response and its description
+type Response struct {
+	Rune rune
+	Desc string
+}
+
+// SortedResponses returns the valid responses and their descriptions
+// sorted by the response. This is the order in which the numeric hotkeys
+// are given (see SetNumericHotkeys). The prompt and the help message show
+// the responses in this order too except that the default, if there is
+// one, is shown first.
+func (r R) SortedResponses() []Response {
+	keys := r.getSortedValidResponses()
+	resps := make([]Response, 0, len(keys))
+
+	for _, k := range keys {
+		resps = append(resps, Response{Rune: k, Desc: r.description(k)})
+	}
+
+	return resps
+}
+
 // PrintHelp prints the help message.
 func (r R) PrintHelp() {
 	r.PrintHelpIndent(r.indent)
@@ -227,32 +1267,69 @@ func (r R) PrintHelp() {
 
 // PrintHelpIndent prints the help message.
 func (r R) PrintHelpIndent(indent int) {
-	twc := twrap.NewTWConfOrPanic()
+	r.printHelp(indent, true)
+}
+
+// PrintHelpIndentNoLead behaves as PrintHelpIndent but without the blank
+// line before the help message. This is useful when the help is being
+// embedded in some larger text which controls its own spacing.
+func (r R) PrintHelpIndentNoLead(indent int) {
+	r.printHelp(indent, false)
+}
+
+// printHelp prints the help message, preceded by a blank line if lead is
+// true.
+func (r R) printHelp(indent int, lead bool) {
+	r, _ = r.resolveDefault()
 
-	twc.Println() //nolint: errcheck
+	r.state.helpShown = true
+
+	twc := twrap.NewTWConfOrPanic(twrap.SetWriter(r.out))
+
+	if lead {
+		twc.Println() //nolint: errcheck
+	}
 	twc.Wrap("Enter one of:", indent)
 
 	keys := r.getSortedValidResponses()
 
-	const charFmt = "%c  "
+	width := displayWidth(r.helpKey(helpRune))
+	for _, k := range keys {
+		if w := displayWidth(r.helpKey(k)); w > width {
+			width = w
+		}
+	}
+
+	marker, pad := "", ""
+	if r.hasDfltMarker {
+		marker = r.dfltMarker
+		pad = strings.Repeat(" ", displayWidth(marker))
+	}
 
 	if r.hasDflt {
-		twc.WrapPrefixed(
-			fmt.Sprintf(charFmt, r.dflt),
-			fmt.Sprintf("%s (this is the default)", r.validResps[r.dflt]),
-			indent+4)
+		if r.hasDfltMarker {
+			twc.WrapPrefixed(
+				marker+helpKeyPrefix(r.helpKey(r.dflt), width),
+				r.description(r.dflt),
+				indent+4)
+		} else {
+			twc.WrapPrefixed(
+				helpKeyPrefix(r.helpKey(r.dflt), width),
+				r.description(r.dflt)+r.dfltHelpSuffix,
+				indent+4)
+		}
 	}
 	for _, k := range keys {
 		if r.hasDflt && r.dflt == k {
 			continue
 		}
 		twc.WrapPrefixed(
-			fmt.Sprintf(charFmt, k),
-			r.validResps[k],
+			pad+helpKeyPrefix(r.helpKey(k), width),
+			r.description(k),
 			indent+4)
 	}
 	twc.WrapPrefixed(
-		fmt.Sprintf(charFmt, helpRune),
+		pad+helpKeyPrefix(r.helpKey(helpRune), width),
 		"to show this message\n",
 		indent+4)
 	twc.Wrap("to select the default either enter the character or whitespace"+
@@ -260,6 +1337,26 @@ func (r R) PrintHelpIndent(indent int) {
 		indent)
 }
 
+// helpKey returns the text used to show the key in the help message
+func (r R) helpKey(k rune) string {
+	if hk, ok := r.hotkeyFor(k); ok {
+		return fmt.Sprintf("%c (%c)", k, hk)
+	}
+
+	return string(k)
+}
+
+// helpKeyPrefix returns the key text padded to the given width followed by
+// a gap so that the descriptions in the help message are aligned
+func helpKeyPrefix(key string, width int) string {
+	pad := width - displayWidth(key)
+	if pad < 0 {
+		pad = 0
+	}
+
+	return key + strings.Repeat(" ", pad) + "  "
+}
+
 // GetResponseOrDie calls GetResponse to get the response but if there is an
 // error it will print it and exit with status 1.
 func (r R) GetResponseOrDie() rune {
@@ -271,15 +1368,30 @@ func (r R) GetResponseOrDie() rune {
 func (r R) GetResponseIndentOrDie(first, second int) rune {
 	resp, err := r.GetResponseIndent(first, second)
 	if err != nil {
-		fmt.Fprintln(os.Stderr)
-		fmt.Fprintln(os.Stderr,
-			strings.Repeat(" ", r.indent)+"    "+err.Error())
-		os.Exit(errExitStatus)
+		r.reportErrAndExit(err)
 	}
 
 	return resp
 }
 
+// reportErrAndExit prints the error (see reportDieErr) and exits with
+// status 1.
+func (r R) reportErrAndExit(err error) {
+	r.reportDieErr(err)
+	os.Exit(errExitStatus)
+}
+
+// reportDieErr prints the error reported by the OrDie methods to the error
+// output, formatted by the die message function if one has been set
+func (r R) reportDieErr(err error) {
+	if r.dieMsg != nil {
+		fmt.Fprintln(r.errOut, r.dieMsg(err))
+		return
+	}
+
+	r.printErr(err, r.indent)
+}
+
 // GetResponse will print the prompt and read a single rune from standard
 // input. It will check that the rune is a valid response. If it is not in
 // the set of valid responses it will print an error message and reprompt. It
@@ -292,63 +1404,591 @@ func (r R) GetResponse() (response rune, err error) {
 }
 
 // GetResponseIndent behaves as GetResponse but the indents are taken from
-// the parameters rather than the responder.
-func (r R) GetResponseIndent(first, second int) (response rune, err error) {
+// the parameters rather than the responder. An indent less than zero is
+// taken as zero.
+func (r R) GetResponseIndent(first, second int) (rune, error) {
+	response, _, err := r.getResponse(first, second)
+
+	return response, err
+}
+
+// getResponse gets the response as for GetResponseIndent and also returns
+// the outcome of asking for it. If a metrics function has been set it is
+// called once the response has been got.
+func (r R) getResponse(first, second int) (rune, Outcome, error) {
+	var m Metrics
+
+	start := time.Now()
+	response, outcome, err := r.askForResponse(first, second, &m)
+
+	if r.metricsFunc != nil {
+		m.Duration = time.Since(start)
+		m.Outcome = outcome
+		m.Rune = response
+		r.metricsFunc(m)
+	}
+
+	return response, outcome, err
+}
+
+// nonNegative returns the indent, or zero if it is less than zero
+func nonNegative(indent int) int {
+	if indent < 0 {
+		return 0
+	}
+
+	return indent
+}
+
+// askForResponse prompts for and reads the response, reprompting as
+// necessary. The counts of reprompts and requests for help are recorded in
+// the Metrics.
+func (r R) askForResponse(first, second int, m *Metrics) (
+	rune, Outcome, error,
+) {
+	r, err := r.resolveDefault()
+	if err != nil {
+		return unicode.ReplacementChar, OutcomeReadError, err
+	}
+
+	first, second = nonNegative(first), nonNegative(second)
+
+	if resp, ok := r.autoSelect(first); ok {
+		return resp, OutcomeAnswered, nil
+	}
+
 	i := 0
 
 	prefix := strings.Repeat(" ", first)
 	secondPrefix := strings.Repeat(" ", second)
+	showFullPrompt := true
+
+	var dedupe errDeduper
+
+	if r.leadingBlankLine {
+		fmt.Fprintln(r.out)
+	}
+
+	if r.requireHelpFirst && !r.state.helpShown {
+		r.showHelp(second)
+	}
+
+	showHint := r.hint != ""
+	hintBeneath := false
+
 	for {
-		fmt.Print(prefix)
+		if showHint {
+			r.printHintBefore(prefix)
+		}
+		if showFullPrompt {
+			r.printColumns(prefix)
+		}
+		fmt.Fprint(r.out, prefix)
+		linePrefix := prefix
 		prefix = secondPrefix
-		r.PrintPrompt()
+		if showFullPrompt {
+			r.PrintPrompt()
+			r.record("prompt", fmt.Sprintf("%q", r.PromptString()))
+			showFullPrompt = !r.tersePrompt
+			if showHint {
+				hintBeneath = r.printHintBeneath(secondPrefix,
+					displayWidth(linePrefix)+r.PromptWidth())
+				showHint = false
+			}
+		} else {
+			fmt.Fprint(r.out, tersePrompt)
+			r.record("prompt", fmt.Sprintf("%q", tersePrompt))
+		}
+		r.promptTime = time.Now()
+		r.state.lineEnded = false
 
-		response, err = r.getResp()
+		response, kind, err := r.getResp()
+		if hintBeneath {
+			// clear the hint before anything else is written over it
+			fmt.Fprint(r.out, clearBelowSeq)
+			hintBeneath = false
+		}
+		if errors.Is(err, errCycleDefault) {
+			r.cycleDefault()
+			r.record("default", string(r.dflt))
+			r.redrawForCycle()
+			prefix = linePrefix
+			showFullPrompt = true
+			continue
+		}
+		if errors.Is(err, errRepeatPrompt) {
+			r.record("repeat", "")
+			fmt.Fprintln(r.out)
+			showFullPrompt = true
+			continue
+		}
 		if response == helpRune {
-			r.PrintHelpIndent(second)
+			r.record("help", "")
+			r.showHelp(second)
+			m.HelpRequested = true
+			showFullPrompt = true
+			// by default a request for help does not count as a reprompt
+			if !r.helpCountsAsReprompt {
+				continue
+			}
+			i++
+			if r.limitPrompts && i > r.maxReprompts {
+				err = errors.New(
+					"no response was given, only requests for help")
+				r.state.lastErr = err
+				return unicode.ReplacementChar, OutcomeTooManyReprompts, err
+			}
+			m.RepromptCount++
 			continue
 		}
 		i++
 
-		if err == nil || err == io.EOF {
-			return
+		r.state.lastErr = err
+
+		if err == nil {
+			r.record("answer", string(response))
+			if r.clearAfter && r.outputIsTerminal() {
+				fmt.Fprint(r.out, clearLineSeq)
+			}
+			if r.hasAbortResp && response == r.abortResp {
+				return response, OutcomeAborted, ErrAborted
+			}
+			if r.hasEditResp && response == r.editResp {
+				resume := r.suspendRaw()
+				*r.editResult, err = runEditor(r.editorOutput())
+				resume()
+				if err != nil {
+					return unicode.ReplacementChar, OutcomeReadError, err
+				}
+			}
+			if r.confirmEcho {
+				fmt.Fprint(r.out,
+					"\n"+secondPrefix+"→ "+r.description(response))
+			}
+			if kind == KindDefault {
+				return response, OutcomeDefaultSelected, nil
+			}
+			return response, OutcomeAnswered, nil
+		}
+
+		r.record("error", err.Error())
+
+		switch {
+		case err == io.EOF:
+			return response, OutcomeReadError, err
+		case errors.Is(err, ErrCancelled):
+			return response, OutcomeCancelled, err
+		case errors.Is(err, ErrTimedOut):
+			return response, OutcomeTimedOut, err
 		}
 
 		if r.limitPrompts && i > r.maxReprompts {
-			return
+			return response, OutcomeTooManyReprompts, err
+		}
+
+		m.RepromptCount++
+
+		if i <= r.errGrace {
+			r.endPromptLine(r.out)
+			continue
+		}
+
+		if r.dedupeErrors {
+			err = dedupe.dedupe(err)
+		}
+		r.printErr(err, second)
+	}
+}
+
+// printErr prints the error on a new line with the given indent
+func (r R) printErr(err error, indent int) {
+	r.endPromptLine(r.errOut)
+	fmt.Fprintln(r.errOut, strings.Repeat(" ", indent)+"    "+err.Error())
+}
+
+// endPromptLine writes a newline to w to end the prompt line unless the
+// line has already been ended, for instance by echoing a rejected key
+func (r R) endPromptLine(w io.Writer) {
+	if r.state.lineEnded {
+		r.state.lineEnded = false
+		return
+	}
+
+	fmt.Fprintln(w)
+}
+
+// LastError returns the error from the most recent invalid response. It is
+// reset to nil when a valid response is read. Note that this state is
+// shared between copies of the responder.
+func (r R) LastError() error {
+	return r.state.lastErr
+}
+
+// PrintLastError prints the error from the most recent invalid response,
+// if there is one, in the same way as it was printed when the response was
+// read. This can be useful when the screen is redrawn.
+func (r R) PrintLastError() {
+	if r.state.lastErr != nil {
+		r.printErr(r.state.lastErr, r.indent)
+	}
+}
+
+// GetOnce prints the prompt, indented by the first indent (or by zero if
+// the indent is less than zero), reads a single rune and classifies it (see
+// Classify). Unlike GetResponseIndent it does not reprompt after an invalid
+// response or print the help message; the caller is expected to act on the
+// Kind returned. The second indent is not used but is taken for consistency
+// with the other methods.
+//
+// If the rune cannot be read the error is returned with the unicode
+// ReplacementChar and a Kind of KindInvalid.
+func (r R) GetOnce(first, _ int) (rune, Kind, error) {
+	r, err := r.resolveDefault()
+	if err != nil {
+		return unicode.ReplacementChar, KindInvalid, err
+	}
+
+	prefix := strings.Repeat(" ", nonNegative(first))
+	r.printColumns(prefix)
+	fmt.Fprint(r.out, prefix)
+	r.PrintPrompt()
+	r.promptTime = time.Now()
+
+	input, err := r.getRune()
+	if err != nil {
+		return unicode.ReplacementChar, KindInvalid, err
+	}
+
+	resp, kind, err := r.classifyHelpFirst(input)
+	if err == nil && !isLineEnd(input) {
+		r.discardLine()
+	}
+
+	return resp, kind, err
+}
+
+// ID returns the identifier of the responder as set by SetID
+func (r R) ID() string {
+	return r.id
+}
+
+// Indents returns the indents for the first and subsequent lines of output
+// as set by SetIndents.
+func (r R) Indents() (first, second int) {
+	return r.indentFirst, r.indent
+}
+
+// GetResponseIndentRelative behaves as GetResponseIndent but the indents
+// are found by adding the parameters to the responder's indents. This is
+// useful when the prompt is to appear within a block of output which is
+// already indented. The extra indents may be negative to reduce the
+// indents but an indent which would be less than zero is taken as zero.
+func (r R) GetResponseIndentRelative(extraFirst, extra int) (rune, error) {
+	return r.GetResponseIndent(r.indentFirst+extraFirst, r.indent+extra)
+}
+
+// GetResponseIndexed behaves as GetResponseIndent but also returns the
+// index of the response in the sorted list of responses (see
+// SortedResponses). Note that this is not the position of the response in
+// the prompt if there is a default as the default is shown first. If there
+// is an error the index is -1.
+func (r R) GetResponseIndexed(first, second int) (int, rune, error) {
+	resp, err := r.GetResponseIndent(first, second)
+	if err != nil {
+		return -1, resp, err
+	}
+
+	for i, k := range r.getSortedValidResponses() {
+		if k == resp {
+			return i, resp, nil
+		}
+	}
+
+	return -1, resp, nil
+}
+
+// GetResponsesUntil repeatedly prompts the user and collects the responses
+// until the user gives the stop response, which must be one of the valid
+// responses. The stop response is not included in the responses returned.
+// The first prompt is indented by the first indent and subsequent prompts
+// by the second; each prompt starts on a new line. The maximum number of
+// reprompts (see SetMaxReprompts) applies to each response separately.
+//
+// If the end of the input is reached, the responses collected so far are
+// returned with the io.EOF error. If any other error is seen, the
+// responses collected so far are returned with the error.
+func (r R) GetResponsesUntil(stop rune, first, second int) ([]rune, error) {
+	if _, ok := r.validResps[stop]; !ok {
+		return nil,
+			fmt.Errorf(
+				"the stop response (%c) is not"+
+					" in the list of valid responses",
+				stop)
+	}
+
+	resps := []rune{}
+	for {
+		resp, err := r.GetResponseIndent(first, second)
+		if err != nil {
+			return resps, err
 		}
+		if resp == stop {
+			return resps, nil
+		}
+
+		resps = append(resps, resp)
+		first = second
+		fmt.Fprintln(r.out)
+	}
+}
+
+// GetResponseNoHelp behaves as GetResponseIndent but help is disabled for
+// this call only; the help rune is not shown in the list of valid
+// responses and is treated as an invalid response.
+func (r R) GetResponseNoHelp(first, second int) (rune, error) {
+	r.noHelp = true
+
+	return r.GetResponseIndent(first, second)
+}
+
+// GetResponseWithPrompt behaves as GetResponseIndent but the prompt is
+// taken from the parameter rather than the responder. The prompt stored in
+// the responder is not changed. Any prompt function (see SetPromptFunc) is
+// not used.
+func (r R) GetResponseWithPrompt(
+	prompt string, first, second int,
+) (rune, error) {
+	r.prompt = prompt
+	r.promptFunc = nil
+
+	return r.GetResponseIndent(first, second)
+}
 
-		fmt.Fprintln(os.Stderr)
-		fmt.Fprintln(os.Stderr, prefix+"    "+err.Error())
+// IsInteractive reports whether the file descriptor that the responder will
+// read from is a terminal. This can be used to decide whether to prompt the
+// user or to proceed with some default value.
+func (r R) IsInteractive() bool {
+	return term.IsTerminal(r.fd)
+}
+
+// outputIsTerminal reports whether the output is a terminal
+func (r R) outputIsTerminal() bool {
+	f, ok := r.out.(interface{ Fd() uintptr })
+	if !ok {
+		return false
+	}
+
+	return term.IsTerminal(int(f.Fd()))
+}
+
+// termMakeRaw puts the terminal into raw mode using the term package and
+// returns a function which will restore it
+func termMakeRaw(fd int) (func() error, error) {
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return nil, err
 	}
+
+	return func() error { return term.Restore(fd, state) }, nil
 }
 
 // getRune gets the response and performs any mappings and display of help
 func (r R) getRune() (rune, error) {
-	state, err := term.MakeRaw(r.fd)
-	if err == nil {
-		defer term.Restore(r.fd, state) //nolint: errcheck
+	if r.hideCursor && r.outputIsTerminal() {
+		fmt.Fprint(r.out, hideCursorSeq)
+		defer fmt.Fprint(r.out, showCursorSeq)
 	}
+
+	if r.fd != noFD {
+		restore, err := r.makeRaw(r.fd)
+		if err == nil {
+			defer restore() //nolint: errcheck
+		}
+	}
+
+	var resp rune
+	var err error
+	// each read, including those after an early key has been discarded,
+	// must wait for the deadline or cancellation first
+	for {
+		if err = r.waitForDeadline(); err != nil {
+			return unicode.ReplacementChar, err
+		}
+		if err = r.waitForDone(); err != nil {
+			return unicode.ReplacementChar, err
+		}
+
+		resp, err = r.readRune()
+		if err != nil || !r.isEarly() {
+			break
+		}
+	}
+	if err == nil && resp == escRune && len(r.fnKeys) > 0 {
+		return r.functionKeyResp()
+	}
+	if err == nil && resp == escRune && r.escCancels {
+		return resp, r.checkEscape()
+	}
+
+	return resp, err
+}
+
+// readRune reads a single rune, or a single byte if the responder is in byte
+// mode. In byte mode a byte which is not ASCII is read as part of a UTF-8
+// sequence so that a key such as 'é' is read, and rejected, as one key
+// rather than as several bytes.
+func (r R) readRune() (rune, error) {
+	if r.byteMode {
+		b, err := r.rdr.ReadByte()
+		if err != nil || b < utf8.RuneSelf {
+			return rune(b), err
+		}
+		r.rdr.UnreadByte() //nolint: errcheck
+	}
+
 	resp, _, err := r.rdr.ReadRune()
 	return resp, err
 }
 
-// getResp gets the response and performs any mappings and display of help
-func (r R) getResp() (rune, error) {
+// getResp gets the response and performs any mappings and display of help.
+// It also returns the kind of the response.
+func (r R) getResp() (rune, Kind, error) {
+	if r.lineMode {
+		return r.getLineResp()
+	}
+
 	resp, err := r.getRune()
+	for err == nil && r.ignoreBackspace && isBackspace(resp) {
+		resp, err = r.getRune()
+	}
 	if err != nil {
-		return unicode.ReplacementChar, err
+		return unicode.ReplacementChar, KindInvalid, err
+	}
+
+	if r.tabCyclesDflt && resp == '\t' {
+		return resp, KindInvalid, errCycleDefault
+	}
+	if r.hasRepeatKey && resp == r.repeatKey {
+		return resp, KindInvalid, errRepeatPrompt
+	}
+
+	input := resp
+	resp, kind, err := r.classifyHelpFirst(input)
+	if err != nil && r.hybridInput && unicode.IsLetter(input) &&
+		r.inputPending() {
+		return r.getHybridResp(input)
+	}
+	if err != nil && r.echoRejected {
+		r.printRejected(input)
+	}
+	if err == nil && !isLineEnd(input) {
+		r.discardLine()
+	}
+
+	return resp, kind, err
+}
+
+// printRejected echoes a key which is not a valid response, in red if the
+// output is a terminal, and ends the prompt line. Keys which are not
+// printable are not echoed.
+func (r R) printRejected(c rune) {
+	if !unicode.IsPrint(c) {
+		return
+	}
+
+	if r.outputIsTerminal() {
+		fmt.Fprintf(r.out, colorStartFmt+"%c"+colorReset+"\n", rejectedColor, c)
+	} else {
+		fmt.Fprintf(r.out, "%c\n", c)
+	}
+
+	r.state.lineEnded = true
+}
+
+// KeyMap returns a map from each key that the user can press to the
+// response that it produces. This includes the responses themselves, their
+// uppercase forms (unless SetNoFold has been given), any numeric hotkeys,
+// the help rune (mapped to itself, if help is enabled) and, if there is a
+// default, the whitespace keys (space, tab, newline and carriage return)
+// which select it. Note that any whitespace character will select the
+// default, not just those listed. If SetTabCyclesDefault has been given
+// then the tab key changes the default rather than selecting it and so it
+// is not included.
+func (r R) KeyMap() map[rune]rune {
+	r, _ = r.resolveDefault()
+
+	km := make(map[rune]rune)
+
+	for k := range r.validResps {
+		km[k] = k
+		if !r.noFold {
+			if uc := unicode.ToUpper(k); uc != k {
+				km[uc] = k
+			}
+		}
+		if hk, ok := r.hotkeyFor(k); ok {
+			km[hk] = k
+		}
 	}
-	if r.hasDflt && unicode.IsSpace(resp) {
-		resp = r.dflt
-	} else if resp != helpRune {
-		resp = unicode.ToLower(resp)
 
-		if _, ok := r.validResps[resp]; !ok {
-			return unicode.ReplacementChar,
-				fmt.Errorf("Bad response: %c", resp)
+	if r.helpEnabled() {
+		km[helpRune] = helpRune
+	}
+
+	if r.hasDflt {
+		for _, ws := range " \t\n\r" {
+			if ws == '\t' && r.tabCyclesDflt {
+				continue
+			}
+			km[ws] = r.dflt
 		}
 	}
 
-	return resp, nil
+	return km
+}
+
+// isBackspace reports whether the rune is one of the characters that a
+// Backspace key might send
+func isBackspace(c rune) bool {
+	return c == '\b' || c == '\x7f'
+}
+
+// Classify applies the same mappings and checks to the input rune as are
+// applied to a rune read from the user. It returns the resulting response,
+// the Kind of the input and an error if the input is not valid.
+//
+// If a default has been set then any whitespace character will be mapped to
+// the default response and the Kind will be KindDefault. The help rune will
+// be returned unchanged with a Kind of KindHelp. Any other rune will be
+// mapped to lowercase (unless SetNoFold has been used) and then checked
+// against the valid responses. If it is not valid the unicode
+// ReplacementChar is returned with a Kind of KindInvalid and an error.
+func (r R) Classify(input rune) (rune, Kind, error) {
+	r, err := r.resolveDefault()
+	if err != nil {
+		return unicode.ReplacementChar, KindInvalid, err
+	}
+
+	if r.hasDflt && unicode.IsSpace(input) {
+		return r.dflt, KindDefault, nil
+	}
+
+	if input == helpRune && r.helpEnabled() {
+		return helpRune, KindHelp, nil
+	}
+
+	if resp, ok := r.hotkeyResp(input); ok {
+		return resp, KindValid, nil
+	}
+
+	resp := input
+	if !r.noFold {
+		resp = unicode.ToLower(input)
+	}
+	if _, ok := r.validResps[resp]; !ok {
+		return unicode.ReplacementChar, KindInvalid,
+			BadResponseError{Rune: input}
+	}
+
+	return resp, KindValid, nil
 }
@@ -0,0 +1,3131 @@
+package responder
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+	"unicode"
+)
+
+func TestClassify(t *testing.T) {
+	resps := map[rune]string{
+		'y': "yes",
+		'n': "no",
+	}
+	noDflt := NewOrPanic("test", resps)
+	noFold := NewOrPanic("test", resps, SetNoFold())
+	hotkeys := NewOrPanic("test", resps, SetNumericHotkeys())
+	hotkeysDflt := NewOrPanic("test", resps,
+		SetNumericHotkeys(), SetDefault('y'))
+	noHelp := NewOrPanic("test", resps, SetNoHelp())
+	withDflt := NewOrPanic("test", resps, SetDefault('n'))
+
+	testCases := []struct {
+		name     string
+		r        *R
+		input    rune
+		expResp  rune
+		expKind  Kind
+		expError bool
+	}{
+		{
+			name:    "valid",
+			r:       noDflt,
+			input:   'y',
+			expResp: 'y',
+			expKind: KindValid,
+		},
+		{
+			name:    "valid, uppercase",
+			r:       noDflt,
+			input:   'Y',
+			expResp: 'y',
+			expKind: KindValid,
+		},
+		{
+			name:     "uppercase, no fold",
+			r:        noFold,
+			input:    'Y',
+			expResp:  unicode.ReplacementChar,
+			expKind:  KindInvalid,
+			expError: true,
+		},
+		{
+			name:    "numeric hotkey",
+			r:       hotkeys,
+			input:   '2',
+			expResp: 'y',
+			expKind: KindValid,
+		},
+		{
+			name:    "numeric hotkey, default first",
+			r:       hotkeysDflt,
+			input:   '1',
+			expResp: 'y',
+			expKind: KindValid,
+		},
+		{
+			name:    "numeric hotkey, after the default",
+			r:       hotkeysDflt,
+			input:   '2',
+			expResp: 'n',
+			expKind: KindValid,
+		},
+		{
+			name:     "numeric hotkey, out of range",
+			r:        hotkeys,
+			input:    '3',
+			expResp:  unicode.ReplacementChar,
+			expKind:  KindInvalid,
+			expError: true,
+		},
+		{
+			name:    "help",
+			r:       noDflt,
+			input:   helpRune,
+			expResp: helpRune,
+			expKind: KindHelp,
+		},
+		{
+			name:     "help, no help",
+			r:        noHelp,
+			input:    helpRune,
+			expResp:  unicode.ReplacementChar,
+			expKind:  KindInvalid,
+			expError: true,
+		},
+		{
+			name:     "whitespace, no default",
+			r:        noDflt,
+			input:    ' ',
+			expResp:  unicode.ReplacementChar,
+			expKind:  KindInvalid,
+			expError: true,
+		},
+		{
+			name:    "whitespace, with default",
+			r:       withDflt,
+			input:   '\n',
+			expResp: 'n',
+			expKind: KindDefault,
+		},
+		{
+			name:     "invalid",
+			r:        withDflt,
+			input:    'x',
+			expResp:  unicode.ReplacementChar,
+			expKind:  KindInvalid,
+			expError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		resp, kind, err := tc.r.Classify(tc.input)
+		if resp != tc.expResp {
+			t.Errorf("%s: expected response: %q, got: %q",
+				tc.name, tc.expResp, resp)
+		}
+		if kind != tc.expKind {
+			t.Errorf("%s: expected kind: %s, got: %s",
+				tc.name, tc.expKind, kind)
+		}
+		if (err != nil) != tc.expError {
+			t.Errorf("%s: unexpected error state: %v", tc.name, err)
+		}
+	}
+}
+
+func TestSetOutput(t *testing.T) {
+	var buf bytes.Buffer
+
+	r := NewOrPanic("test",
+		map[rune]string{
+			'y': "yes",
+			'n': "no",
+		},
+		SetDefault('y'),
+		SetOutput(&buf))
+
+	r.PrintPrompt()
+	if buf.String() != "test? ([y]/n/?): " {
+		t.Errorf("unexpected prompt: %q", buf.String())
+	}
+
+	buf.Reset()
+	r.PrintHelp()
+	if !strings.Contains(buf.String(), "yes (this is the default)") {
+		t.Errorf("the help was not written to the output: %q", buf.String())
+	}
+}
+
+func TestSetOutputSplitStreams(t *testing.T) {
+	resps := map[rune]string{
+		'y': "yes",
+		'n': "no",
+	}
+
+	testCases := []struct {
+		name       string
+		input      string
+		opts       []RespOptFunc
+		expResp    rune
+		expPrompts int
+		expOutput  []string
+	}{
+		{
+			name:       "default via whitespace",
+			input:      " ",
+			opts:       []RespOptFunc{SetDefault('y')},
+			expResp:    'y',
+			expPrompts: 1,
+			expOutput:  []string{"test? ([y]/n/?): "},
+		},
+		{
+			name:       "help then an answer",
+			input:      "?n",
+			expResp:    'n',
+			expPrompts: 2,
+			expOutput:  []string{"Enter one of:", "y  yes"},
+		},
+		{
+			name:       "help then the default via whitespace",
+			input:      "?\n",
+			opts:       []RespOptFunc{SetDefault('y')},
+			expResp:    'y',
+			expPrompts: 2,
+			expOutput: []string{
+				"Enter one of:",
+				"y  yes (this is the default)",
+			},
+		},
+	}
+
+	// anything written to the standard output is captured so that it can
+	// be checked that nothing is written there
+	stdout := os.Stdout
+	defer func() { os.Stdout = stdout }()
+
+	for _, tc := range testCases {
+		pr, pw, err := os.Pipe()
+		if err != nil {
+			t.Fatalf("%s: cannot create the pipe: %v", tc.name, err)
+		}
+		os.Stdout = pw
+
+		var out bytes.Buffer
+		opts := append([]RespOptFunc{
+			SetInput(strings.NewReader(tc.input)),
+			SetOutput(&out),
+		}, tc.opts...)
+		r, err := New("test", resps, opts...)
+		if err != nil {
+			t.Fatalf("%s: unexpected error creating the responder: %v",
+				tc.name, err)
+		}
+
+		resp, err := r.GetResponse()
+
+		os.Stdout = stdout
+		pw.Close()
+		stdoutText, _ := io.ReadAll(pr)
+		pr.Close()
+
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+		}
+		if resp != tc.expResp {
+			t.Errorf("%s: expected response %q, got: %q",
+				tc.name, tc.expResp, resp)
+		}
+		if len(stdoutText) != 0 {
+			t.Errorf("%s: unexpected standard output: %q",
+				tc.name, string(stdoutText))
+		}
+		if n := strings.Count(out.String(), "test? ("); n != tc.expPrompts {
+			t.Errorf("%s: expected %d prompts, got: %d",
+				tc.name, tc.expPrompts, n)
+		}
+		for _, exp := range tc.expOutput {
+			if !strings.Contains(out.String(), exp) {
+				t.Errorf("%s: expected output containing %q, got: %q",
+					tc.name, exp, out.String())
+			}
+		}
+	}
+}
+
+// errAny is used in tests to indicate that some error is expected
+var errAny = errors.New("any error")
+
+func TestGetResponse(t *testing.T) {
+	resps := map[rune]string{
+		'y': "yes",
+		'n': "no",
+	}
+
+	testCases := []struct {
+		name      string
+		input     string
+		opts      []RespOptFunc
+		get       func(r R) (rune, error)
+		expResp   rune
+		expErr    error
+		expOutput string
+	}{
+		{
+			name:      "valid",
+			input:     "y",
+			expResp:   'y',
+			expOutput: "test? (n/y/?): ",
+		},
+		{
+			name:      "default",
+			input:     "\n",
+			opts:      []RespOptFunc{SetDefault('n')},
+			expResp:   'n',
+			expOutput: "test? ([n]/y/?): ",
+		},
+		{
+			name:  "default func",
+			input: "\n",
+			opts: []RespOptFunc{
+				SetDefault('n'),
+				SetDefaultFunc(func() (rune, bool) { return 'y', true }),
+			},
+			expResp:   'y',
+			expOutput: "test? ([y]/n/?): ",
+		},
+		{
+			name:  "default func, bad default",
+			input: "\n",
+			opts: []RespOptFunc{
+				SetDefaultFunc(func() (rune, bool) { return 'x', true }),
+			},
+			expResp: unicode.ReplacementChar,
+			expErr:  errAny,
+		},
+		{
+			name:      "leading blank line",
+			input:     "y",
+			opts:      []RespOptFunc{SetLeadingBlankLine()},
+			expResp:   'y',
+			expOutput: "\ntest? (n/y/?): ",
+		},
+		{
+			name:  "non-interactive default",
+			input: "\n",
+			opts: []RespOptFunc{
+				SetInteractiveDefault('y'),
+				SetNonInteractiveDefault('n'),
+			},
+			expResp:   'n',
+			expOutput: "test? ([n]/y/?): ",
+		},
+		{
+			name:      "ignore backspace",
+			input:     "\x7f\by",
+			opts:      []RespOptFunc{SetIgnoreBackspace()},
+			expResp:   'y',
+			expOutput: "test? (n/y/?): ",
+		},
+		{
+			name:    "confirm echo, rejected key not echoed",
+			input:   "xy",
+			opts:    []RespOptFunc{SetConfirmEcho()},
+			expResp: 'y',
+			expOutput: "test? (n/y/?): \n    bad response: x\n" +
+				"test? (n/y/?): \n→ yes",
+		},
+		{
+			name:    "rejected key echoed",
+			input:   "xy",
+			opts:    []RespOptFunc{SetEchoRejected()},
+			expResp: 'y',
+			expOutput: "test? (n/y/?): x\n    bad response: x\n" +
+				"test? (n/y/?): ",
+		},
+		{
+			name:    "rejected key echoed, error grace",
+			input:   "xy",
+			opts:    []RespOptFunc{SetEchoRejected(), SetErrorGrace(1)},
+			expResp: 'y',
+			expOutput: "test? (n/y/?): x\n" +
+				"test? (n/y/?): ",
+		},
+		{
+			name:      "tab cycles default",
+			input:     "\t\n",
+			opts:      []RespOptFunc{SetDefault('n'), SetTabCyclesDefault()},
+			expResp:   'y',
+			expOutput: "test? ([n]/y/?): \ntest? ([y]/n/?): ",
+		},
+		{
+			name:    "tab cycles default, no default",
+			input:   "\t\t\t ",
+			opts:    []RespOptFunc{SetTabCyclesDefault()},
+			expResp: 'n',
+			expOutput: "test? (n/y/?): \n" +
+				"test? ([n]/y/?): \n" +
+				"test? ([y]/n/?): \n" +
+				"test? ([n]/y/?): ",
+		},
+		{
+			name:      "repeat key",
+			input:     "rry",
+			opts:      []RespOptFunc{SetRepeatKey('r'), SetMaxReprompts(1)},
+			expResp:   'y',
+			expOutput: "test? (n/y/?): \ntest? (n/y/?): \ntest? (n/y/?): ",
+		},
+		{
+			name:  "hint, not a terminal",
+			input: "xy",
+			opts: []RespOptFunc{
+				SetHint("(press ? for details)"),
+				SetIndents(1, 2),
+			},
+			expResp: 'y',
+			expOutput: " (press ? for details)\n" +
+				" test? (n/y/?): \n" +
+				"      bad response: x\n" +
+				"  test? (n/y/?): ",
+		},
+		{
+			name:      "error grace",
+			input:     "xy",
+			opts:      []RespOptFunc{SetErrorGrace(1)},
+			expResp:   'y',
+			expOutput: "test? (n/y/?): \ntest? (n/y/?): ",
+		},
+		{
+			name:  "keys pressed too early",
+			input: "yn",
+			opts: []RespOptFunc{
+				SetMinVisibleTime(time.Hour),
+				SetBeepOnEarlyKey(),
+			},
+			expResp:   unicode.ReplacementChar,
+			expErr:    io.EOF,
+			expOutput: "test? (n/y/?): \a\a",
+		},
+		{
+			name:    "byte mode, multi-byte key rejected once",
+			input:   "éy",
+			opts:    []RespOptFunc{SetByteMode(), SetMaxReprompts(1)},
+			expResp: 'y',
+			expOutput: "test? (n/y/?): \n    bad response: é\n" +
+				"test? (n/y/?): ",
+		},
+		{
+			name:  "default help suffix",
+			input: "?\n",
+			opts: []RespOptFunc{
+				SetDefault('n'),
+				SetDefaultHelpSuffix(" - the default"),
+			},
+			expResp: 'n',
+			expOutput: "test? ([n]/y/?): \n" +
+				"Enter one of:\n" +
+				"    n  no - the default\n" +
+				"    y  yes\n" +
+				"    ?  to show this message\n" +
+				"\n" +
+				"to select the default either enter the character or" +
+				" whitespace (a space, tab or\n" +
+				"return character)\n" +
+				"test? ([n]/y/?): ",
+		},
+		{
+			name:      "hide cursor, not a terminal",
+			input:     "y",
+			opts:      []RespOptFunc{SetHideCursor()},
+			expResp:   'y',
+			expOutput: "test? (n/y/?): ",
+		},
+		{
+			name:  "prompt given at call time",
+			input: "xy",
+			opts:  []RespOptFunc{SetPromptFunc(func() string { return "func" })},
+			get: func(r R) (rune, error) {
+				return r.GetResponseWithPrompt("other", 2, 4)
+			},
+			expResp: 'y',
+			expOutput: "  other? (n/y/?): \n        bad response: x\n" +
+				"    other? (n/y/?): ",
+		},
+		{
+			name:    "terse prompt",
+			input:   "xzy",
+			opts:    []RespOptFunc{SetTersePrompt()},
+			expResp: 'y',
+			expOutput: "test? (n/y/?): \n    bad response: x\n" +
+				"> \n    bad response: z\n" +
+				"> ",
+		},
+		{
+			name:    "terse prompt, full prompt after help",
+			input:   "x?y",
+			opts:    []RespOptFunc{SetTersePrompt()},
+			expResp: 'y',
+			expOutput: "test? (n/y/?): \n    bad response: x\n" +
+				"> \n" +
+				"Enter one of:\n" +
+				"    n  no\n" +
+				"    y  yes\n" +
+				"    ?  to show this message\n" +
+				"\n" +
+				"to select the default either enter the character or" +
+				" whitespace (a space, tab or\n" +
+				"return character)\n" +
+				"test? (n/y/?): ",
+		},
+		{
+			name:      "clear after, not a terminal",
+			input:     "y",
+			opts:      []RespOptFunc{SetClearAfter()},
+			expResp:   'y',
+			expOutput: "test? (n/y/?): ",
+		},
+		{
+			name:    "no help",
+			input:   "?y",
+			opts:    []RespOptFunc{SetNoHelp()},
+			expResp: 'y',
+			expOutput: "test? (n/y): \n    bad response: ?\n" +
+				"test? (n/y): ",
+		},
+		{
+			name:  "no help for this call",
+			input: "?y",
+			get: func(r R) (rune, error) {
+				return r.GetResponseNoHelp(0, 0)
+			},
+			expResp: 'y',
+			expOutput: "test? (n/y): \n    bad response: ?\n" +
+				"test? (n/y): ",
+		},
+		{
+			name:  "help shown again after a call without help",
+			input: "yn",
+			get: func(r R) (rune, error) {
+				if _, err := r.GetResponseNoHelp(0, 0); err != nil {
+					return unicode.ReplacementChar, err
+				}
+				return r.GetResponse()
+			},
+			expResp:   'n',
+			expOutput: "test? (n/y): test? (n/y/?): ",
+		},
+		{
+			name:      "key pressed after the minimum time",
+			input:     "y",
+			opts:      []RespOptFunc{SetMinVisibleTime(time.Nanosecond)},
+			expResp:   'y',
+			expOutput: "test? (n/y/?): ",
+		},
+		{
+			name:      "EOF",
+			input:     "",
+			expResp:   unicode.ReplacementChar,
+			expErr:    io.EOF,
+			expOutput: "test? (n/y/?): ",
+		},
+		{
+			name:    "abort",
+			input:   "n",
+			opts:    []RespOptFunc{SetAbortResponse('n')},
+			expResp: 'n',
+			expErr:  ErrAborted,
+		},
+	}
+
+	for _, tc := range testCases {
+		r, buf, err := NewTestResponder(tc.input, resps, tc.opts...)
+		if err != nil {
+			t.Fatalf("%s: unexpected error creating the responder: %v",
+				tc.name, err)
+		}
+
+		get := R.GetResponse
+		if tc.get != nil {
+			get = tc.get
+		}
+
+		resp, err := get(*r)
+		if resp != tc.expResp {
+			t.Errorf("%s: expected response: %q, got: %q",
+				tc.name, tc.expResp, resp)
+		}
+		if tc.expErr == errAny {
+			if err == nil {
+				t.Errorf("%s: expected an error, got none", tc.name)
+			}
+		} else if !errors.Is(err, tc.expErr) {
+			t.Errorf("%s: expected error: %v, got: %v",
+				tc.name, tc.expErr, err)
+		}
+		if tc.expOutput != "" && buf.String() != tc.expOutput {
+			t.Errorf("%s: expected output: %q, got: %q",
+				tc.name, tc.expOutput, buf.String())
+		}
+	}
+}
+
+func TestGetResponseHelp(t *testing.T) {
+	r, buf, err := NewTestResponder("?y",
+		map[rune]string{
+			'y': "yes",
+			'n': "no",
+		})
+	if err != nil {
+		t.Fatalf("unexpected error creating the responder: %v", err)
+	}
+
+	resp, err := r.GetResponse()
+	if resp != 'y' || err != nil {
+		t.Errorf("expected 'y' and no error, got: %q, %v", resp, err)
+	}
+
+	if !strings.Contains(buf.String(), "Enter one of:") {
+		t.Errorf("the help message was not shown: %q", buf.String())
+	}
+	if strings.Count(buf.String(), "test? (n/y/?): ") != 2 {
+		t.Errorf("the prompt was not shown twice: %q", buf.String())
+	}
+}
+
+func TestHelpPager(t *testing.T) {
+	testCases := []struct {
+		name      string
+		pager     string
+		expPrefix string
+	}{
+		{
+			name:      "pager",
+			pager:     "sed -e s/^/paged:/",
+			expPrefix: "paged:",
+		},
+		{
+			name:  "bad pager - fall back to printing",
+			pager: "/no/such/pager",
+		},
+	}
+
+	for _, tc := range testCases {
+		r, buf, err := NewTestResponder("?y",
+			map[rune]string{
+				'y': "yes",
+				'n': "no",
+			},
+			SetHelpPager(tc.pager))
+		if err != nil {
+			t.Fatalf("%s: unexpected error creating the responder: %v",
+				tc.name, err)
+		}
+
+		resp, err := r.GetResponse()
+		if resp != 'y' || err != nil {
+			t.Errorf("%s: expected 'y' and no error, got: %q, %v",
+				tc.name, resp, err)
+		}
+
+		if !strings.Contains(buf.String(), tc.expPrefix+"Enter one of:") {
+			t.Errorf("%s: the help message was not shown: %q",
+				tc.name, buf.String())
+		}
+	}
+}
+
+func TestHelpCountsAsReprompt(t *testing.T) {
+	testCases := []struct {
+		name    string
+		input   string
+		opts    []RespOptFunc
+		expResp rune
+		expErr  bool
+	}{
+		{
+			name:    "help is not counted by default",
+			input:   "???y",
+			opts:    []RespOptFunc{SetMaxReprompts(1)},
+			expResp: 'y',
+		},
+		{
+			name:  "help is not counted",
+			input: "???y",
+			opts: []RespOptFunc{
+				SetMaxReprompts(1),
+				SetHelpCountsAsReprompt(false),
+			},
+			expResp: 'y',
+		},
+		{
+			name:  "help is counted - within the limit",
+			input: "?y",
+			opts: []RespOptFunc{
+				SetMaxReprompts(1),
+				SetHelpCountsAsReprompt(true),
+			},
+			expResp: 'y',
+		},
+		{
+			name:  "help is counted - too many",
+			input: "??y",
+			opts: []RespOptFunc{
+				SetMaxReprompts(1),
+				SetHelpCountsAsReprompt(true),
+			},
+			expResp: unicode.ReplacementChar,
+			expErr:  true,
+		},
+		{
+			name:  "help is counted - with an invalid response",
+			input: "?xy",
+			opts: []RespOptFunc{
+				SetMaxReprompts(1),
+				SetHelpCountsAsReprompt(true),
+			},
+			expResp: unicode.ReplacementChar,
+			expErr:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		r, _, err := NewTestResponder(tc.input,
+			map[rune]string{
+				'y': "yes",
+				'n': "no",
+			},
+			tc.opts...)
+		if err != nil {
+			t.Fatalf("%s: unexpected error creating the responder: %v",
+				tc.name, err)
+		}
+
+		resp, err := r.GetResponse()
+		if resp != tc.expResp {
+			t.Errorf("%s: expected response %q, got: %q",
+				tc.name, tc.expResp, resp)
+		}
+		if (err != nil) != tc.expErr {
+			t.Errorf("%s: unexpected error state: %v", tc.name, err)
+		}
+	}
+}
+
+func TestLastError(t *testing.T) {
+	r, _, err := NewTestResponder("",
+		map[rune]string{
+			'y': "yes",
+			'n': "no",
+		})
+	if err != nil {
+		t.Fatalf("unexpected error creating the responder: %v", err)
+	}
+
+	if _, err = r.GetResponse(); err != io.EOF {
+		t.Errorf("expected io.EOF, got: %v", err)
+	}
+	if r.LastError() != io.EOF {
+		t.Errorf("expected the last error to be io.EOF, got: %v",
+			r.LastError())
+	}
+}
+
+func TestNew(t *testing.T) {
+	testCases := []struct {
+		name     string
+		resps    map[rune]string
+		opts     []RespOptFunc
+		expError bool
+	}{
+		{
+			name:  "good",
+			resps: map[rune]string{'y': "yes", 'n': "no"},
+		},
+		{
+			name:     "too few",
+			resps:    map[rune]string{'y': "yes"},
+			expError: true,
+		},
+		{
+			name:     "uppercase",
+			resps:    map[rune]string{'Y': "yes", 'n': "no"},
+			expError: true,
+		},
+		{
+			name:     "whitespace",
+			resps:    map[rune]string{' ': "yes", 'n': "no"},
+			expError: true,
+		},
+		{
+			name:     "help rune",
+			resps:    map[rune]string{'?': "yes", 'n': "no"},
+			expError: true,
+		},
+		{
+			name:     "bad description",
+			resps:    map[rune]string{'y': "yes\nreally", 'n': "no"},
+			expError: true,
+		},
+		{
+			name:     "not printable",
+			resps:    map[rune]string{'\x01': "yes", 'n': "no"},
+			expError: true,
+		},
+		{
+			name:  "not printable, byte mode",
+			resps: map[rune]string{'\x01': "yes", 'n': "no"},
+			opts:  []RespOptFunc{SetByteMode()},
+		},
+		{
+			name:     "byte mode, multi-byte response",
+			resps:    map[rune]string{'é': "yes", 'n': "no"},
+			opts:     []RespOptFunc{SetByteMode()},
+			expError: true,
+		},
+		{
+			name:  "forbidden default, not the default",
+			resps: map[rune]string{'y': "yes", 'n': "no"},
+			opts:  []RespOptFunc{SetForbidDefault('y', 'd'), SetDefault('n')},
+		},
+		{
+			name:     "forbidden default",
+			resps:    map[rune]string{'y': "yes", 'n': "no"},
+			opts:     []RespOptFunc{SetDefault('y'), SetForbidDefault('y')},
+			expError: true,
+		},
+		{
+			name:  "forbidden interactive default",
+			resps: map[rune]string{'y': "yes", 'n': "no"},
+			opts: []RespOptFunc{
+				SetForbidDefault('y'),
+				SetInteractiveDefault('y'),
+			},
+			expError: true,
+		},
+		{
+			name:     "forbid default, no responses",
+			resps:    map[rune]string{'y': "yes", 'n': "no"},
+			opts:     []RespOptFunc{SetForbidDefault()},
+			expError: true,
+		},
+		{
+			name:     "repeat key is a response",
+			resps:    map[rune]string{'y': "yes", 'n': "no"},
+			opts:     []RespOptFunc{SetRepeatKey('y')},
+			expError: true,
+		},
+		{
+			name:     "repeat key is an uppercase response",
+			resps:    map[rune]string{'y': "yes", 'n': "no"},
+			opts:     []RespOptFunc{SetRepeatKey('Y')},
+			expError: true,
+		},
+		{
+			name:  "repeat key is an uppercase response, no fold",
+			resps: map[rune]string{'y': "yes", 'n': "no"},
+			opts:  []RespOptFunc{SetRepeatKey('Y'), SetNoFold()},
+		},
+		{
+			name:  "repeat key is a numeric hotkey",
+			resps: map[rune]string{'y': "yes", 'n': "no"},
+			opts: []RespOptFunc{
+				SetRepeatKey('1'),
+				SetNumericHotkeys(),
+			},
+			expError: true,
+		},
+		{
+			name:  "repeat key is a digit, no numeric hotkeys",
+			resps: map[rune]string{'y': "yes", 'n': "no"},
+			opts:  []RespOptFunc{SetRepeatKey('1')},
+		},
+		{
+			name:  "line mode, tab cycles the default",
+			resps: map[rune]string{'y': "yes", 'n': "no"},
+			opts: []RespOptFunc{
+				SetLineMode(),
+				SetTabCyclesDefault(),
+			},
+			expError: true,
+		},
+		{
+			name:  "line mode, escape cancels",
+			resps: map[rune]string{'y': "yes", 'n': "no"},
+			opts: []RespOptFunc{
+				SetLineMode(),
+				SetEscapeCancels(),
+			},
+			expError: true,
+		},
+		{
+			name:  "escape timeout",
+			resps: map[rune]string{'y': "yes", 'n': "no"},
+			opts: []RespOptFunc{
+				SetEscapeCancels(),
+				SetEscapeTimeout(10 * time.Millisecond),
+			},
+		},
+		{
+			name:     "escape timeout, zero",
+			resps:    map[rune]string{'y': "yes", 'n': "no"},
+			opts:     []RespOptFunc{SetEscapeTimeout(0)},
+			expError: true,
+		},
+		{
+			name:     "escape timeout, negative",
+			resps:    map[rune]string{'y': "yes", 'n': "no"},
+			opts:     []RespOptFunc{SetEscapeTimeout(-time.Second)},
+			expError: true,
+		},
+		{
+			name:  "no help, help pager",
+			resps: map[rune]string{'y': "yes", 'n': "no"},
+			opts: []RespOptFunc{
+				SetNoHelp(),
+				SetHelpPager("less"),
+			},
+			expError: true,
+		},
+		{
+			name:     "bad reader buffer size",
+			resps:    map[rune]string{'y': "yes", 'n': "no"},
+			opts:     []RespOptFunc{SetReaderBufferSize(0)},
+			expError: true,
+		},
+		{
+			name:     "bad response colour code",
+			resps:    map[rune]string{'y': "yes", 'n': "no"},
+			opts:     []RespOptFunc{SetResponseColor('y', "\x1b[31m")},
+			expError: true,
+		},
+		{
+			name:     "response colour for an unknown response",
+			resps:    map[rune]string{'y': "yes", 'n': "no"},
+			opts:     []RespOptFunc{SetResponseColor('x', "31")},
+			expError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		_, err := New("test", tc.resps, tc.opts...)
+		if (err != nil) != tc.expError {
+			t.Errorf("%s: unexpected error state: %v", tc.name, err)
+		}
+	}
+}
+
+func TestReaderBufferSize(t *testing.T) {
+	resps := map[rune]string{'y': "yes", 'n': "no"}
+
+	testCases := []struct {
+		name    string
+		opts    []RespOptFunc
+		expSize int
+	}{
+		{
+			name:    "size before input",
+			opts:    []RespOptFunc{SetReaderBufferSize(32), SetInput(os.Stdin)},
+			expSize: 32,
+		},
+		{
+			name:    "size after input",
+			opts:    []RespOptFunc{SetInput(os.Stdin), SetReaderBufferSize(32)},
+			expSize: 32,
+		},
+		{
+			name:    "size with file",
+			opts:    []RespOptFunc{SetReaderBufferSize(64), SetFile(os.Stdin)},
+			expSize: 64,
+		},
+		{
+			name:    "default size",
+			opts:    []RespOptFunc{SetInput(os.Stdin)},
+			expSize: 4096,
+		},
+	}
+
+	for _, tc := range testCases {
+		r, err := New("test", resps, tc.opts...)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.name, err)
+		}
+		if size := r.rdr.Size(); size != tc.expSize {
+			t.Errorf("%s: expected a buffer size of %d, got: %d",
+				tc.name, tc.expSize, size)
+		}
+	}
+}
+
+func TestSetRawModeFuncs(t *testing.T) {
+	f, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatalf("cannot open %s: %v", os.DevNull, err)
+	}
+	defer f.Close()
+
+	var made, restored int
+
+	r := NewOrPanic("test",
+		map[rune]string{
+			'y': "yes",
+			'n': "no",
+		},
+		SetInput(f),
+		SetOutput(io.Discard),
+		SetRawModeFuncs(func(_ int) (func() error, error) {
+			made++
+			return func() error {
+				restored++
+				return nil
+			}, nil
+		}))
+
+	if _, err = r.GetResponse(); err != io.EOF {
+		t.Errorf("expected io.EOF, got: %v", err)
+	}
+	if made != 1 || restored != 1 {
+		t.Errorf("raw mode set %d times and restored %d times, expected 1",
+			made, restored)
+	}
+}
+
+func TestGetOnce(t *testing.T) {
+	r, buf, err := NewTestResponder("?",
+		map[rune]string{
+			'y': "yes",
+			'n': "no",
+		})
+	if err != nil {
+		t.Fatalf("unexpected error creating the responder: %v", err)
+	}
+
+	resp, kind, err := r.GetOnce(2, 4)
+	if resp != helpRune || kind != KindHelp || err != nil {
+		t.Errorf("expected %q, %s and no error, got: %q, %s, %v",
+			helpRune, KindHelp, resp, kind, err)
+	}
+	if buf.String() != "  test? (n/y/?): " {
+		t.Errorf("unexpected output: %q", buf.String())
+	}
+}
+
+func TestIndents(t *testing.T) {
+	const prompt = "test? (n/y/?): "
+
+	testCases := []struct {
+		name        string
+		opts        []RespOptFunc
+		extraFirst  int
+		extra       int
+		expFirst    int
+		expSecond   int
+		expFirstPfx string
+		expPfx      string
+	}{
+		{
+			name:        "no indents",
+			expFirstPfx: "",
+			expPfx:      "",
+		},
+		{
+			name:        "indents",
+			opts:        []RespOptFunc{SetIndents(2, 4)},
+			expFirst:    2,
+			expSecond:   4,
+			expFirstPfx: "  ",
+			expPfx:      "    ",
+		},
+		{
+			name:        "relative indents",
+			opts:        []RespOptFunc{SetIndents(2, 4)},
+			extraFirst:  1,
+			extra:       2,
+			expFirst:    2,
+			expSecond:   4,
+			expFirstPfx: "   ",
+			expPfx:      "      ",
+		},
+		{
+			name:        "relative indents, reduced",
+			opts:        []RespOptFunc{SetIndents(2, 4)},
+			extraFirst:  -1,
+			extra:       -2,
+			expFirst:    2,
+			expSecond:   4,
+			expFirstPfx: " ",
+			expPfx:      "  ",
+		},
+		{
+			name:        "relative indents, below zero",
+			opts:        []RespOptFunc{SetIndents(2, 4)},
+			extraFirst:  -5,
+			extra:       -10,
+			expFirst:    2,
+			expSecond:   4,
+			expFirstPfx: "",
+			expPfx:      "",
+		},
+	}
+
+	for _, tc := range testCases {
+		r, buf, err := NewTestResponder("xy",
+			map[rune]string{
+				'y': "yes",
+				'n': "no",
+			},
+			tc.opts...)
+		if err != nil {
+			t.Fatalf("%s: unexpected error creating the responder: %v",
+				tc.name, err)
+		}
+
+		if first, second := r.Indents(); first != tc.expFirst ||
+			second != tc.expSecond {
+			t.Errorf("%s: expected indents %d, %d, got: %d, %d",
+				tc.name, tc.expFirst, tc.expSecond, first, second)
+		}
+
+		resp, err := r.GetResponseIndentRelative(tc.extraFirst, tc.extra)
+		if resp != 'y' || err != nil {
+			t.Errorf("%s: expected 'y' and no error, got: %q, %v",
+				tc.name, resp, err)
+		}
+
+		expOut := tc.expFirstPfx + prompt +
+			"\n" + tc.expPfx + "    bad response: x\n" +
+			tc.expPfx + prompt
+		if buf.String() != expOut {
+			t.Errorf("%s: expected output %q, got: %q",
+				tc.name, expOut, buf.String())
+		}
+	}
+
+	r, buf, err := NewTestResponder("y",
+		map[rune]string{
+			'y': "yes",
+			'n': "no",
+		})
+	if err != nil {
+		t.Fatalf("unexpected error creating the responder: %v", err)
+	}
+	if _, _, err = r.GetOnce(-1, -1); err != nil {
+		t.Errorf("GetOnce: unexpected error: %v", err)
+	}
+	if buf.String() != prompt {
+		t.Errorf("GetOnce: expected output %q, got: %q", prompt, buf.String())
+	}
+}
+
+func TestLineMode(t *testing.T) {
+	resps := map[rune]string{
+		'y': "yes, delete the file",
+		'n': "no, leave the file",
+		'q': "quit",
+		'x': "no, and stop asking",
+	}
+
+	testCases := []struct {
+		name     string
+		input    string
+		opts     []RespOptFunc
+		expResp  rune
+		expError bool
+	}{
+		{
+			name:    "single rune",
+			input:   "y\n",
+			expResp: 'y',
+		},
+		{
+			name:    "word",
+			input:   "Yes\n",
+			expResp: 'y',
+		},
+		{
+			name:    "word, no newline",
+			input:   "quit",
+			expResp: 'q',
+		},
+		{
+			name:    "default",
+			input:   "\n",
+			expResp: 'q',
+		},
+		{
+			name:     "ambiguous",
+			input:    "no,\n",
+			expResp:  unicode.ReplacementChar,
+			expError: true,
+		},
+		{
+			name:    "surrounding space trimmed",
+			input:   " quit \n",
+			expResp: 'q',
+		},
+		{
+			name:     "surrounding space not trimmed",
+			input:    " quit \n",
+			opts:     []RespOptFunc{SetTrimInput(false)},
+			expResp:  unicode.ReplacementChar,
+			expError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		opts := append([]RespOptFunc{SetLineMode(), SetDefault('q')},
+			tc.opts...)
+		r, _, err := NewTestResponder(tc.input, resps, opts...)
+		if err != nil {
+			t.Fatalf("%s: unexpected error creating the responder: %v",
+				tc.name, err)
+		}
+
+		resp, _, err := r.getResp()
+		if resp != tc.expResp {
+			t.Errorf("%s: expected response: %q, got: %q",
+				tc.name, tc.expResp, resp)
+		}
+		if (err != nil) != tc.expError {
+			t.Errorf("%s: unexpected error state: %v", tc.name, err)
+		}
+	}
+}
+
+func TestHybridInput(t *testing.T) {
+	resps := map[rune]string{
+		'd': "delete the file",
+		'k': "keep the file",
+		'x': "examine the file",
+		'z': "examine the zip file",
+	}
+
+	testCases := []struct {
+		name     string
+		input    string
+		expResp  rune
+		expError bool
+	}{
+		{
+			name:    "single key",
+			input:   "k",
+			expResp: 'k',
+		},
+		{
+			name:    "word",
+			input:   "Examine the file\n",
+			expResp: 'x',
+		},
+		{
+			name:    "word, no newline",
+			input:   "examine the f",
+			expResp: 'x',
+		},
+		{
+			name:     "ambiguous word",
+			input:    "examine\n",
+			expResp:  unicode.ReplacementChar,
+			expError: true,
+		},
+		{
+			name:     "unknown word",
+			input:    "quit\n",
+			expResp:  unicode.ReplacementChar,
+			expError: true,
+		},
+		{
+			name:     "not a letter",
+			input:    "#",
+			expResp:  unicode.ReplacementChar,
+			expError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		r, _, err := NewTestResponder(tc.input, resps, SetHybridInput())
+		if err != nil {
+			t.Fatalf("%s: unexpected error creating the responder: %v",
+				tc.name, err)
+		}
+
+		resp, _, err := r.getResp()
+		if resp != tc.expResp {
+			t.Errorf("%s: expected response %q, got: %q",
+				tc.name, tc.expResp, resp)
+		}
+		if (err != nil) != tc.expError {
+			t.Errorf("%s: unexpected error state: %v", tc.name, err)
+		}
+	}
+}
+
+func TestDigitResponses(t *testing.T) {
+	resps := map[rune]string{
+		'1': "the first option",
+		'2': "the second option",
+		'0': "none of them",
+	}
+
+	r, buf, err := NewTestResponder("?2", resps, SetDefault('0'))
+	if err != nil {
+		t.Fatalf("unexpected error creating the responder: %v", err)
+	}
+
+	if s := r.ValidResponsesString(); s != "([0]/1/2/?): " {
+		t.Errorf("unexpected list of valid responses: %q", s)
+	}
+
+	resp, err := r.GetResponse()
+	if resp != '2' || err != nil {
+		t.Errorf("expected '2' and no error, got: %q, %v", resp, err)
+	}
+
+	for _, exp := range []string{
+		"0  none of them (this is the default)",
+		"1  the first option",
+		"2  the second option",
+	} {
+		if !strings.Contains(buf.String(), exp) {
+			t.Errorf("the help message does not contain %q: %q",
+				exp, buf.String())
+		}
+	}
+
+	if _, err = New("test", resps, SetNumericHotkeys()); err == nil {
+		t.Errorf("digit responses and numeric hotkeys should not be allowed")
+	}
+
+	if _, err = New("test",
+		map[rune]string{'0': "zero", 'a': "a"},
+		SetNumericHotkeys()); err != nil {
+		t.Errorf("a response of '0' should be allowed with numeric hotkeys: %v",
+			err)
+	}
+}
+
+func TestNumericHotkeysPrompt(t *testing.T) {
+	resps := map[rune]string{
+		'y': "yes",
+		'n': "no",
+		'q': "quit",
+	}
+
+	testCases := []struct {
+		name      string
+		opts      []RespOptFunc
+		expPrompt string
+		expHelp   []string
+	}{
+		{
+			name:      "no default",
+			expPrompt: "test? (n(1)/q(2)/y(3)/?): ",
+			expHelp:   []string{"n (1)  no", "q (2)  quit", "y (3)  yes"},
+		},
+		{
+			name:      "with default",
+			opts:      []RespOptFunc{SetDefault('y')},
+			expPrompt: "test? ([y(1)]/n(2)/q(3)/?): ",
+			expHelp: []string{
+				"y (1)  yes (this is the default)",
+				"n (2)  no",
+				"q (3)  quit",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		var buf bytes.Buffer
+		opts := append([]RespOptFunc{SetNumericHotkeys(), SetOutput(&buf)},
+			tc.opts...)
+		r := NewOrPanic("test", resps, opts...)
+
+		if p := r.PromptString(); p != tc.expPrompt {
+			t.Errorf("%s: expected prompt %q, got: %q",
+				tc.name, tc.expPrompt, p)
+		}
+
+		r.PrintHelp()
+		for _, exp := range tc.expHelp {
+			if !strings.Contains(buf.String(), exp) {
+				t.Errorf("%s: the help message does not contain %q: %q",
+					tc.name, exp, buf.String())
+			}
+		}
+	}
+}
+
+func TestResponseStream(t *testing.T) {
+	r, _, err := NewTestResponder("yxn",
+		map[rune]string{
+			'y': "yes",
+			'n': "no",
+		})
+	if err != nil {
+		t.Fatalf("unexpected error creating the responder: %v", err)
+	}
+
+	expKinds := []Kind{KindValid, KindInvalid, KindValid}
+	i := 0
+	for ev := range r.ResponseStream(context.Background()) {
+		if i >= len(expKinds) {
+			t.Fatalf("too many events: %v", ev)
+		}
+		if ev.Kind != expKinds[i] {
+			t.Errorf("event %d: expected kind: %s, got: %s",
+				i, expKinds[i], ev.Kind)
+		}
+		if (ev.Err != nil) != (expKinds[i] == KindInvalid) {
+			t.Errorf("event %d: unexpected error state: %v", i, ev.Err)
+		}
+		i++
+	}
+	if i != len(expKinds) {
+		t.Errorf("expected %d events, got %d", len(expKinds), i)
+	}
+}
+
+func TestGetResponsesUntil(t *testing.T) {
+	resps := map[rune]string{
+		'a': "add an apple",
+		'b': "add a banana",
+		'q': "stop adding fruit",
+	}
+
+	testCases := []struct {
+		name     string
+		input    string
+		expResps string
+		expErr   error
+	}{
+		{
+			name:     "stopped",
+			input:    "abaq",
+			expResps: "aba",
+		},
+		{
+			name:     "EOF",
+			input:    "ab",
+			expResps: "ab",
+			expErr:   io.EOF,
+		},
+	}
+
+	for _, tc := range testCases {
+		r, _, err := NewTestResponder(tc.input, resps)
+		if err != nil {
+			t.Fatalf("%s: unexpected error creating the responder: %v",
+				tc.name, err)
+		}
+
+		got, err := r.GetResponsesUntil('q', 0, 0)
+		if string(got) != tc.expResps {
+			t.Errorf("%s: expected responses: %q, got: %q",
+				tc.name, tc.expResps, string(got))
+		}
+		if err != tc.expErr {
+			t.Errorf("%s: expected error: %v, got: %v",
+				tc.name, tc.expErr, err)
+		}
+	}
+}
+
+func TestGetResponseIndexed(t *testing.T) {
+	r, _, err := NewTestResponder("b",
+		map[rune]string{
+			'c': "third",
+			'a': "first",
+			'b': "second",
+		})
+	if err != nil {
+		t.Fatalf("unexpected error creating the responder: %v", err)
+	}
+
+	idx, resp, err := r.GetResponseIndexed(0, 0)
+	if idx != 1 || resp != 'b' || err != nil {
+		t.Errorf("expected 1, 'b' and no error, got: %d, %q, %v",
+			idx, resp, err)
+	}
+}
+
+func TestInputPending(t *testing.T) {
+	r, _, err := NewTestResponder("y",
+		map[rune]string{
+			'y': "yes",
+			'n': "no",
+		})
+	if err != nil {
+		t.Fatalf("unexpected error creating the responder: %v", err)
+	}
+
+	if _, err = r.InputPending(); err == nil {
+		t.Error("expected an error for input which is not a file")
+	}
+
+	f, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatalf("cannot open %s: %v", os.DevNull, err)
+	}
+	defer f.Close()
+
+	r, err = New("test",
+		map[rune]string{
+			'y': "yes",
+			'n': "no",
+		},
+		SetFile(f),
+		SetOutput(io.Discard))
+	if err != nil {
+		t.Fatalf("unexpected error creating the responder: %v", err)
+	}
+
+	pending, err := r.InputPending()
+	if err != nil {
+		t.Errorf("unexpected error checking for pending input: %v", err)
+	}
+	if !pending {
+		t.Errorf("expected input to be pending (EOF) on %s", os.DevNull)
+	}
+}
+
+func TestGetFixedLength(t *testing.T) {
+	allowed := map[string]string{
+		"de": "Germany",
+		"fr": "France",
+		"us": "United States",
+	}
+
+	testCases := []struct {
+		name      string
+		n         int
+		allowed   map[string]string
+		input     string
+		opts      []RespOptFunc
+		expCode   string
+		expErr    bool
+		expOutput string
+	}{
+		{
+			name:      "valid",
+			n:         2,
+			allowed:   allowed,
+			input:     "fr",
+			expCode:   "fr",
+			expOutput: "country? (de/fr/us/?): fr",
+		},
+		{
+			name:      "folded",
+			n:         2,
+			allowed:   allowed,
+			input:     "US",
+			expCode:   "us",
+			expOutput: "country? (de/fr/us/?): US",
+		},
+		{
+			name:      "backspace",
+			n:         2,
+			allowed:   allowed,
+			input:     "f\x7fde",
+			expCode:   "de",
+			expOutput: "country? (de/fr/us/?): f\b \bde",
+		},
+		{
+			name:    "bad then good",
+			n:       2,
+			allowed: allowed,
+			input:   "xxde",
+			expCode: "de",
+			expOutput: "country? (de/fr/us/?): xx\n" +
+				"    bad response: xx\n" +
+				"country? (de/fr/us/?): de",
+		},
+		{
+			name:    "too many bad codes",
+			n:       2,
+			allowed: allowed,
+			input:   "xxyyde",
+			opts:    []RespOptFunc{SetMaxReprompts(1)},
+			expErr:  true,
+		},
+		{
+			name:    "EOF",
+			n:       2,
+			allowed: allowed,
+			input:   "d",
+			expErr:  true,
+		},
+		{
+			name:    "bad length",
+			n:       3,
+			allowed: allowed,
+			input:   "usa",
+			expErr:  true,
+		},
+		{
+			name:    "bad code",
+			n:       2,
+			allowed: map[string]string{"a?": "bad"},
+			input:   "a?",
+			expErr:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		var out bytes.Buffer
+
+		opts := append([]RespOptFunc{
+			SetInput(strings.NewReader(tc.input)),
+			SetOutput(&out),
+			SetErrOutput(&out),
+		}, tc.opts...)
+
+		code, err := GetFixedLength("country", tc.n, tc.allowed, opts...)
+		if (err != nil) != tc.expErr {
+			t.Errorf("%s: unexpected error state: %v", tc.name, err)
+		}
+		if code != tc.expCode {
+			t.Errorf("%s: expected code %q, got: %q",
+				tc.name, tc.expCode, code)
+		}
+		if tc.expOutput != "" && out.String() != tc.expOutput {
+			t.Errorf("%s: expected output %q, got: %q",
+				tc.name, tc.expOutput, out.String())
+		}
+	}
+}
+
+func TestCompile(t *testing.T) {
+	dflt := 'n'
+	r, err := New("test",
+		map[rune]string{
+			'y': "yes",
+			'n': "no",
+		},
+		SetDefaultFunc(func() (rune, bool) { return dflt, true }))
+	if err != nil {
+		t.Fatalf("unexpected error creating the responder: %v", err)
+	}
+
+	r.Compile()
+	if r.compiled == nil {
+		t.Fatal("the responses list was not compiled")
+	}
+	// change the cached list so that we can tell when it is used
+	r.compiled.list = "compiled"
+
+	testCases := []struct {
+		name      string
+		dflt      rune
+		noHelp    bool
+		expPrompt string
+	}{
+		{
+			name:      "no default - cache used",
+			dflt:      0,
+			expPrompt: "test? (compiled): ",
+		},
+		{
+			name:      "default changed - cache not used",
+			dflt:      'y',
+			expPrompt: "test? ([y]/n/?): ",
+		},
+		{
+			name:      "no help - cache not used",
+			dflt:      0,
+			noHelp:    true,
+			expPrompt: "test? (n/y): ",
+		},
+	}
+
+	for _, tc := range testCases {
+		r2 := *r
+		r2.noHelp = tc.noHelp
+		r2.dfltFunc = func() (rune, bool) { return tc.dflt, tc.dflt != 0 }
+
+		if prompt := r2.PromptString(); prompt != tc.expPrompt {
+			t.Errorf("%s: expected prompt %q, got: %q",
+				tc.name, tc.expPrompt, prompt)
+		}
+	}
+}
+
+func TestDedupeErrors(t *testing.T) {
+	errX := errors.New("bad response: x")
+	errY := errors.New("bad response: y")
+
+	var ed errDeduper
+
+	for i, tc := range []struct {
+		err    error
+		expMsg string
+	}{
+		{err: errX, expMsg: "bad response: x"},
+		{err: errX, expMsg: "bad response: x (x2)"},
+		{err: errX, expMsg: "bad response: x (x3)"},
+		{err: errY, expMsg: "bad response: y"},
+		{err: errX, expMsg: "bad response: x"},
+	} {
+		err := ed.dedupe(tc.err)
+		if err.Error() != tc.expMsg {
+			t.Errorf("%d: expected %q, got: %q", i, tc.expMsg, err.Error())
+		}
+		if !errors.Is(err, tc.err) {
+			t.Errorf("%d: the original error is not wrapped", i)
+		}
+	}
+}
+
+func TestBadResponseError(t *testing.T) {
+	r, _, err := NewTestResponder("xZ",
+		map[rune]string{
+			'y': "yes",
+			'n': "no",
+		},
+		SetMaxReprompts(1))
+	if err != nil {
+		t.Fatalf("unexpected error creating the responder: %v", err)
+	}
+
+	_, err = r.GetResponse()
+
+	var bre BadResponseError
+	if !errors.As(err, &bre) {
+		t.Fatalf("expected a BadResponseError, got: %v", err)
+	}
+	if bre.Rune != 'Z' {
+		t.Errorf("expected the bad rune to be 'Z', got: %q", bre.Rune)
+	}
+	if err.Error() != "bad response: Z" {
+		t.Errorf("unexpected error message: %q", err.Error())
+	}
+}
+
+func TestRequireHelpFirst(t *testing.T) {
+	resps := map[rune]string{
+		'y': "yes",
+		'n': "no",
+	}
+
+	r, buf, err := NewTestResponder("yy", resps, SetRequireHelpFirst())
+	if err != nil {
+		t.Fatalf("unexpected error creating the responder: %v", err)
+	}
+
+	if _, kind, err := r.GetOnce(0, 0); err == nil || kind != KindInvalid {
+		t.Errorf("expected the response to be rejected before the help"+
+			" was shown, got: %s, %v", kind, err)
+	}
+
+	r.PrintHelp()
+
+	if resp, _, err := r.GetOnce(0, 0); resp != 'y' || err != nil {
+		t.Errorf("expected 'y' and no error once the help was shown,"+
+			" got: %q, %v", resp, err)
+	}
+
+	r, buf, err = NewTestResponder("y", resps, SetRequireHelpFirst())
+	if err != nil {
+		t.Fatalf("unexpected error creating the responder: %v", err)
+	}
+
+	if resp, err := r.GetResponse(); resp != 'y' || err != nil {
+		t.Errorf("expected 'y' and no error, got: %q, %v", resp, err)
+	}
+	if !strings.HasPrefix(buf.String(), "\nEnter one of:") {
+		t.Errorf("the help was not shown before the prompt: %q", buf.String())
+	}
+}
+
+func TestConnResponder(t *testing.T) {
+	conn, remote := net.Pipe()
+
+	r, err := NewConnResponder(conn, "test",
+		map[rune]string{
+			'y': "yes",
+			'n': "no",
+		})
+	if err != nil {
+		t.Fatalf("unexpected error creating the responder: %v", err)
+	}
+
+	var out bytes.Buffer
+	done := make(chan struct{})
+	go func() {
+		_, _ = io.Copy(&out, remote)
+		close(done)
+	}()
+	go func() {
+		_, _ = remote.Write([]byte("x\ny\n"))
+	}()
+
+	resp, err := r.GetResponse()
+	conn.Close()
+	<-done
+
+	if resp != 'y' || err != nil {
+		t.Errorf("expected 'y' and no error, got: %q, %v", resp, err)
+	}
+	if !strings.Contains(out.String(), "bad response: x") {
+		t.Errorf("the error was not written to the connection: %q",
+			out.String())
+	}
+	if strings.Count(out.String(), "test? (n/y/?): ") != 2 {
+		t.Errorf("the prompt was not written twice: %q", out.String())
+	}
+}
+
+func TestGetResponseE(t *testing.T) {
+	resps := map[rune]string{
+		'y': "yes",
+		'n': "no",
+		'q': "quit",
+	}
+
+	testCases := []struct {
+		name       string
+		input      string
+		opts       []RespOptFunc
+		expResp    rune
+		expOutcome Outcome
+	}{
+		{
+			name:       "answered",
+			input:      "y",
+			expResp:    'y',
+			expOutcome: OutcomeAnswered,
+		},
+		{
+			name:       "default key",
+			input:      "n",
+			opts:       []RespOptFunc{SetDefault('n')},
+			expResp:    'n',
+			expOutcome: OutcomeAnswered,
+		},
+		{
+			name:       "default selected",
+			input:      " ",
+			opts:       []RespOptFunc{SetDefault('n')},
+			expResp:    'n',
+			expOutcome: OutcomeDefaultSelected,
+		},
+		{
+			name:       "default selected, line mode",
+			input:      "\n",
+			opts:       []RespOptFunc{SetDefault('n'), SetLineMode()},
+			expResp:    'n',
+			expOutcome: OutcomeDefaultSelected,
+		},
+		{
+			name:       "aborted",
+			input:      "q",
+			opts:       []RespOptFunc{SetAbortResponse('q')},
+			expResp:    'q',
+			expOutcome: OutcomeAborted,
+		},
+		{
+			name:       "cancelled",
+			input:      "\x1b",
+			opts:       []RespOptFunc{SetEscapeCancels()},
+			expResp:    unicode.ReplacementChar,
+			expOutcome: OutcomeCancelled,
+		},
+		{
+			name:       "too many reprompts",
+			input:      "xx",
+			opts:       []RespOptFunc{SetMaxReprompts(1)},
+			expResp:    unicode.ReplacementChar,
+			expOutcome: OutcomeTooManyReprompts,
+		},
+		{
+			name:       "EOF",
+			input:      "",
+			expResp:    unicode.ReplacementChar,
+			expOutcome: OutcomeReadError,
+		},
+	}
+
+	for _, tc := range testCases {
+		r, _, err := NewTestResponder(tc.input, resps, tc.opts...)
+		if err != nil {
+			t.Fatalf("%s: unexpected error creating the responder: %v",
+				tc.name, err)
+		}
+
+		resp, outcome, err := r.GetResponseE(0, 0)
+		if resp != tc.expResp {
+			t.Errorf("%s: expected response %q, got: %q",
+				tc.name, tc.expResp, resp)
+		}
+		if outcome != tc.expOutcome {
+			t.Errorf("%s: expected outcome %s, got: %s (error: %v)",
+				tc.name, tc.expOutcome, outcome, err)
+		}
+		if (err != nil) == (outcome == OutcomeAnswered ||
+			outcome == OutcomeDefaultSelected) {
+			t.Errorf("%s: unexpected error state: %v", tc.name, err)
+		}
+	}
+}
+
+func TestFunctionKeys(t *testing.T) {
+	resps := map[rune]string{
+		'y': "yes",
+		'n': "no",
+	}
+
+	testCases := []struct {
+		name      string
+		input     string
+		opts      []RespOptFunc
+		expResp   rune
+		expErr    bool
+		expErrStr string
+	}{
+		{
+			name:    "F1, xterm",
+			input:   "\x1bOP",
+			opts:    []RespOptFunc{SetFunctionKey(1, 'y')},
+			expResp: 'y',
+		},
+		{
+			name:    "F1, rxvt",
+			input:   "\x1b[11~",
+			opts:    []RespOptFunc{SetFunctionKey(1, 'y')},
+			expResp: 'y',
+		},
+		{
+			name:    "F1, Linux console",
+			input:   "\x1b[[A",
+			opts:    []RespOptFunc{SetFunctionKey(1, 'y')},
+			expResp: 'y',
+		},
+		{
+			name:  "F12",
+			input: "\x1b[24~",
+			opts: []RespOptFunc{
+				SetFunctionKey(1, 'y'),
+				SetFunctionKey(12, 'n'),
+			},
+			expResp: 'n',
+		},
+		{
+			name:    "unmapped key then a valid response",
+			input:   "\x1b[24~y",
+			opts:    []RespOptFunc{SetFunctionKey(1, 'y')},
+			expResp: 'y',
+		},
+		{
+			name:  "overridden sequence",
+			input: "\x1b[99~",
+			opts: []RespOptFunc{
+				SetFunctionKey(1, 'n'),
+				SetFunctionKeySequence(1, "\x1b[99~"),
+			},
+			expResp: 'n',
+		},
+		{
+			name:  "overridden sequence, default no longer used",
+			input: "\x1bOPy",
+			opts: []RespOptFunc{
+				SetFunctionKey(1, 'n'),
+				SetFunctionKeySequence(1, "\x1b[99~"),
+			},
+			expResp: 'y',
+		},
+		{
+			name:      "bad key number",
+			opts:      []RespOptFunc{SetFunctionKey(13, 'y')},
+			expErr:    true,
+			expErrStr: "SetFunctionKey: the function key (F13) must be from F1 to F12",
+		},
+		{
+			name:   "bad response",
+			opts:   []RespOptFunc{SetFunctionKey(1, 'x')},
+			expErr: true,
+			expErrStr: "SetFunctionKey: the response (x) is not" +
+				" in the list of valid responses",
+		},
+		{
+			name:   "bad sequence",
+			opts:   []RespOptFunc{SetFunctionKeySequence(1, "OP")},
+			expErr: true,
+			expErrStr: `SetFunctionKeySequence: the sequence ("OP") must be` +
+				" an Escape character followed by at least one character",
+		},
+	}
+
+	for _, tc := range testCases {
+		r, _, err := NewTestResponder(tc.input, resps, tc.opts...)
+		if tc.expErr {
+			if err == nil {
+				t.Errorf("%s: an error was expected but not seen", tc.name)
+			} else if err.Error() != tc.expErrStr {
+				t.Errorf("%s: expected error: %q\n\tgot: %q",
+					tc.name, tc.expErrStr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: unexpected error creating the responder: %v",
+				tc.name, err)
+		}
+
+		resp, err := r.GetResponse()
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+		}
+		if resp != tc.expResp {
+			t.Errorf("%s: expected response %q, got: %q",
+				tc.name, tc.expResp, resp)
+		}
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	resps := map[rune]string{
+		'y': "yes",
+		'n': "no",
+	}
+
+	testCases := []struct {
+		name       string
+		input      string
+		opts       []RespOptFunc
+		expMetrics Metrics
+	}{
+		{
+			name:  "answered",
+			input: "y",
+			expMetrics: Metrics{
+				Outcome: OutcomeAnswered,
+				Rune:    'y',
+			},
+		},
+		{
+			name:  "reprompted, with help",
+			input: "x?zn",
+			expMetrics: Metrics{
+				RepromptCount: 2,
+				HelpRequested: true,
+				Outcome:       OutcomeAnswered,
+				Rune:          'n',
+			},
+		},
+		{
+			name:  "default selected",
+			input: " ",
+			opts:  []RespOptFunc{SetDefault('n')},
+			expMetrics: Metrics{
+				Outcome: OutcomeDefaultSelected,
+				Rune:    'n',
+			},
+		},
+		{
+			name:  "too many reprompts",
+			input: "xxx",
+			opts:  []RespOptFunc{SetMaxReprompts(1)},
+			expMetrics: Metrics{
+				RepromptCount: 1,
+				Outcome:       OutcomeTooManyReprompts,
+				Rune:          unicode.ReplacementChar,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		var calls []Metrics
+		opts := append([]RespOptFunc{
+			SetMetrics(func(m Metrics) { calls = append(calls, m) }),
+		}, tc.opts...)
+
+		r, _, err := NewTestResponder(tc.input, resps, opts...)
+		if err != nil {
+			t.Fatalf("%s: unexpected error creating the responder: %v",
+				tc.name, err)
+		}
+
+		_, _ = r.GetResponse()
+		if len(calls) != 1 {
+			t.Errorf("%s: expected 1 call of the metrics function, got: %d",
+				tc.name, len(calls))
+			continue
+		}
+		m := calls[0]
+		if m.Duration < 0 {
+			t.Errorf("%s: bad duration: %s", tc.name, m.Duration)
+		}
+		m.Duration = 0
+		if m != tc.expMetrics {
+			t.Errorf("%s: expected metrics: %+v\n\tgot: %+v",
+				tc.name, tc.expMetrics, m)
+		}
+	}
+
+	r, _, err := NewTestResponder("y", resps, SetMetrics(nil))
+	if err != nil {
+		t.Fatalf("unexpected error creating the responder: %v", err)
+	}
+	if resp, err := r.GetResponse(); resp != 'y' || err != nil {
+		t.Errorf("with nil metrics function: expected 'y', got: %q (%v)",
+			resp, err)
+	}
+}
+
+func TestGetResponseWithCancel(t *testing.T) {
+	resps := map[rune]string{
+		'y': "yes",
+		'n': "no",
+	}
+
+	r, _, err := NewTestResponder("y", resps)
+	if err != nil {
+		t.Fatalf("unexpected error creating the responder: %v", err)
+	}
+
+	done := make(chan struct{})
+	resp, err := r.GetResponseWithCancel(done, 0, 0)
+	if resp != 'y' || err != nil {
+		t.Errorf("not cancelled: expected 'y' and no error, got: %q, %v",
+			resp, err)
+	}
+
+	close(done)
+	resp, err = r.GetResponseWithCancel(done, 0, 0)
+	if resp != unicode.ReplacementChar || !errors.Is(err, ErrCancelled) {
+		t.Errorf("cancelled: expected %q and ErrCancelled, got: %q, %v",
+			unicode.ReplacementChar, resp, err)
+	}
+}
+
+func TestForbidDefault(t *testing.T) {
+	resps := map[rune]string{
+		'd': "delete",
+		'k': "keep",
+		'q': "quit",
+	}
+
+	_, err := New("test", resps, SetDefault('d'), SetForbidDefault('d'))
+	expErr := "SetDefault: the default response (d) is forbidden" +
+		" - it has been marked as a dangerous response" +
+		" which must never be the default (see SetForbidDefault)"
+	if err == nil || err.Error() != expErr {
+		t.Errorf("expected error: %q\n\tgot: %v", expErr, err)
+	}
+
+	r, _, err := NewTestResponder("", resps,
+		SetForbidDefault('d'),
+		SetDefaultFunc(func() (rune, bool) { return 'd', true }))
+	if err != nil {
+		t.Fatalf("unexpected error creating the responder: %v", err)
+	}
+	if _, _, err = r.Default(); err == nil {
+		t.Error("expected an error from a forbidden default function")
+	}
+
+	r, _, err = NewTestResponder("\t\t\n", resps,
+		SetForbidDefault('d'),
+		SetTabCyclesDefault(),
+		SetDefault('q'))
+	if err != nil {
+		t.Fatalf("unexpected error creating the responder: %v", err)
+	}
+	resp, err := r.GetResponse()
+	if resp != 'q' || err != nil {
+		t.Errorf("Tab cycling should skip the forbidden response:"+
+			" expected 'q', got: %q, %v",
+			resp, err)
+	}
+}
+
+func TestCaptureRaw(t *testing.T) {
+	resps := map[rune]string{
+		'y': "yes",
+		'n': "no",
+	}
+
+	var raw []byte
+	r, _, err := NewTestResponder("\x1b[Ax\x1bOPy", resps,
+		SetCaptureRaw(&raw))
+	if err != nil {
+		t.Fatalf("unexpected error creating the responder: %v", err)
+	}
+
+	resp, err := r.GetResponse()
+	if resp != 'y' || err != nil {
+		t.Errorf("expected 'y' and no error, got: %q, %v", resp, err)
+	}
+	if exp := "\x1b[Ax\x1bOPy"; string(raw) != exp {
+		t.Errorf("expected the raw input: %q\n\tgot: %q", exp, raw)
+	}
+
+	if _, err = New("test", resps, SetCaptureRaw(nil)); err == nil {
+		t.Error("expected an error with a nil buffer")
+	}
+}
+
+func TestGetResponseAmong(t *testing.T) {
+	resps := map[rune]string{
+		'c': "copy",
+		'm': "move",
+		'd': "delete",
+	}
+
+	testCases := []struct {
+		name      string
+		input     string
+		allowed   []rune
+		opts      []RespOptFunc
+		expResp   rune
+		expErr    bool
+		chkOutput bool
+		expOutput string
+	}{
+		{
+			name:    "narrowed",
+			input:   "dm",
+			allowed: []rune{'c', 'm'},
+			expResp: 'm',
+		},
+		{
+			name:    "default not among the responses",
+			input:   " c",
+			allowed: []rune{'c', 'm'},
+			opts:    []RespOptFunc{SetDefault('d')},
+			expResp: 'c',
+		},
+		{
+			name:    "single response, prompted",
+			input:   "c",
+			allowed: []rune{'c'},
+			expResp: 'c',
+		},
+		{
+			name:      "single response, auto-selected",
+			allowed:   []rune{'c'},
+			opts:      []RespOptFunc{SetAutoSelectSingle(false)},
+			expResp:   'c',
+			chkOutput: true,
+			expOutput: "",
+		},
+		{
+			name:      "single response, auto-selected with notice",
+			allowed:   []rune{'c'},
+			opts:      []RespOptFunc{SetAutoSelectSingle(true)},
+			expResp:   'c',
+			chkOutput: true,
+			expOutput: "test: c (the only valid response)\n",
+		},
+		{
+			name:    "not a valid response",
+			allowed: []rune{'c', 'x'},
+			expErr:  true,
+		},
+		{
+			name:   "no responses",
+			expErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		r, out, err := NewTestResponder(tc.input, resps, tc.opts...)
+		if err != nil {
+			t.Fatalf("%s: unexpected error creating the responder: %v",
+				tc.name, err)
+		}
+
+		resp, err := r.GetResponseAmong(tc.allowed, 0, 0)
+		if tc.expErr {
+			if err == nil {
+				t.Errorf("%s: an error was expected but not seen", tc.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+		}
+		if resp != tc.expResp {
+			t.Errorf("%s: expected response %q, got: %q",
+				tc.name, tc.expResp, resp)
+		}
+		if tc.chkOutput && out.String() != tc.expOutput {
+			t.Errorf("%s: expected output: %q\n\tgot: %q",
+				tc.name, tc.expOutput, out.String())
+		}
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	resps := map[rune]string{
+		'y': "yes",
+		'n': "no",
+		's': "start over",
+	}
+
+	testCases := []struct {
+		name       string
+		input      string
+		retryOn    rune
+		maxRetries int
+		expResp    rune
+		expBuilds  int
+		expErr     bool
+	}{
+		{
+			name:       "no retry",
+			input:      "y",
+			retryOn:    's',
+			maxRetries: 2,
+			expResp:    'y',
+			expBuilds:  1,
+		},
+		{
+			name:       "retried",
+			input:      "ssn",
+			retryOn:    's',
+			maxRetries: 2,
+			expResp:    'n',
+			expBuilds:  3,
+		},
+		{
+			name:       "too many retries",
+			input:      "sssn",
+			retryOn:    's',
+			maxRetries: 2,
+			expResp:    unicode.ReplacementChar,
+			expBuilds:  3,
+			expErr:     true,
+		},
+		{
+			name:       "retry response not valid",
+			input:      "y",
+			retryOn:    'x',
+			maxRetries: 2,
+			expResp:    unicode.ReplacementChar,
+			expBuilds:  1,
+			expErr:     true,
+		},
+	}
+
+	for _, tc := range testCases {
+		// the input is read a byte at a time so that each responder built
+		// reads only its own response
+		in := iotest.OneByteReader(strings.NewReader(tc.input))
+		builds := 0
+		rr := NewRetryable(func() (*R, error) {
+			builds++
+			return New("test", resps, SetInput(in), SetOutput(io.Discard))
+		}, tc.retryOn)
+		rr.SetMaxRetries(tc.maxRetries)
+
+		resp, err := rr.GetResponse()
+		if tc.expErr != (err != nil) {
+			t.Errorf("%s: unexpected error state: %v", tc.name, err)
+		}
+		if resp != tc.expResp {
+			t.Errorf("%s: expected response %q, got: %q",
+				tc.name, tc.expResp, resp)
+		}
+		if builds != tc.expBuilds {
+			t.Errorf("%s: expected %d builds, got: %d",
+				tc.name, tc.expBuilds, builds)
+		}
+	}
+}
+
+func TestValidateResponses(t *testing.T) {
+	testCases := []struct {
+		name      string
+		resps     map[rune]string
+		help      rune
+		expErrStr string
+	}{
+		{
+			name:  "good",
+			resps: map[rune]string{'y': "yes", 'n': "no"},
+			help:  '?',
+		},
+		{
+			name:      "too few",
+			resps:     map[rune]string{'y': "yes"},
+			help:      '?',
+			expErrStr: "too few allowed responses - there must be at least 2",
+		},
+		{
+			name:      "uppercase",
+			resps:     map[rune]string{'Y': "yes", 'n': "no"},
+			help:      '?',
+			expErrStr: "only lowercase responses are allowed - 'Y' is uppercase",
+		},
+		{
+			name:  "whitespace",
+			resps: map[rune]string{' ': "yes", 'n': "no"},
+			help:  '?',
+			expErrStr: "a whitespace character is not an allowed response" +
+				" - it is used to select the default response",
+		},
+		{
+			name:  "help rune",
+			resps: map[rune]string{'?': "yes", 'n': "no"},
+			help:  '?',
+			expErrStr: "'?' is not an allowed response" +
+				" - it is used to request help",
+		},
+		{
+			name:  "other help rune",
+			resps: map[rune]string{'?': "yes", 'n': "no"},
+			help:  'h',
+		},
+		{
+			name:  "bad description",
+			resps: map[rune]string{'y': "yes\nreally", 'n': "no"},
+			help:  '?',
+			expErrStr: "the description of 'y' contains a control character" +
+				" (U+000A) - only printable characters and spaces are allowed",
+		},
+	}
+
+	for _, tc := range testCases {
+		err := ValidateResponses(tc.resps, tc.help)
+		if tc.expErrStr == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", tc.name, err)
+			}
+			continue
+		}
+		if err == nil || err.Error() != tc.expErrStr {
+			t.Errorf("%s: expected error: %q\n\tgot: %v",
+				tc.name, tc.expErrStr, err)
+		}
+	}
+}
+
+func TestColumnarPromptNotTerminal(t *testing.T) {
+	resps := map[rune]string{
+		'a': "add",
+		'b': "build",
+		'c': "clean",
+		'd': "deploy",
+		'e': "edit",
+		'f': "fetch",
+	}
+
+	r, out, err := NewTestResponder("a", resps, SetColumnarPrompt())
+	if err != nil {
+		t.Fatalf("unexpected error creating the responder: %v", err)
+	}
+
+	if _, err = r.GetResponse(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if exp := "test? (a/b/c/d/e/f/?): "; out.String() != exp {
+		t.Errorf("expected the one-line prompt: %q\n\tgot: %q",
+			exp, out.String())
+	}
+}
+
+func TestGetResponseWithCancelNotPollable(t *testing.T) {
+	pr, pw := io.Pipe()
+	defer pw.Close()
+
+	r, err := New("test", map[rune]string{'y': "yes", 'n': "no"},
+		SetInput(pr), SetOutput(io.Discard))
+	if err != nil {
+		t.Fatalf("unexpected error creating the responder: %v", err)
+	}
+	// a file descriptor which cannot be polled, as on a platform where
+	// polling is not supported
+	r.fd = 9999
+
+	type result struct {
+		resp rune
+		err  error
+	}
+	resCh := make(chan result, 1)
+	done := make(chan struct{})
+	go func() {
+		resp, err := r.GetResponseWithCancel(done, 0, 0)
+		resCh <- result{resp: resp, err: err}
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	close(done)
+
+	select {
+	case res := <-resCh:
+		if !errors.Is(res.err, ErrCancelled) {
+			t.Errorf("expected ErrCancelled, got: %q, %v", res.resp, res.err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the prompt to be cancelled")
+	}
+}
+
+func TestBatchResponder(t *testing.T) {
+	resps := map[rune]string{
+		'y': "yes",
+		'n': "no",
+	}
+	allYesNo := map[rune]rune{
+		'a': 'y',
+		'd': 'n',
+	}
+
+	testCases := []struct {
+		name        string
+		input       string
+		allResps    map[rune]rune
+		resetAfter  int
+		expResps    []rune
+		expDecision rune
+		expDecided  bool
+		expErr      bool
+	}{
+		{
+			name:     "no 'apply to all' response chosen",
+			input:    "yn",
+			allResps: allYesNo,
+			expResps: []rune{'y', 'n'},
+		},
+		{
+			name:        "yes to all",
+			input:       "a",
+			allResps:    allYesNo,
+			expResps:    []rune{'y', 'y', 'y'},
+			expDecision: 'y',
+			expDecided:  true,
+		},
+		{
+			name:        "no to all after a single response",
+			input:       "yd",
+			allResps:    allYesNo,
+			expResps:    []rune{'y', 'n', 'n'},
+			expDecision: 'n',
+			expDecided:  true,
+		},
+		{
+			name:       "reset after the decision",
+			input:      "ay",
+			allResps:   allYesNo,
+			resetAfter: 1,
+			expResps:   []rune{'y', 'y'},
+		},
+		{
+			name:   "no 'apply to all' responses",
+			expErr: true,
+		},
+		{
+			name:     "'apply to all' response already a response",
+			allResps: map[rune]rune{'y': 'n'},
+			expErr:   true,
+		},
+		{
+			name:     "'apply to all' response maps to a bad response",
+			allResps: map[rune]rune{'a': 'x'},
+			expErr:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		br, err := NewBatchResponder("test", resps, tc.allResps,
+			SetInput(strings.NewReader(tc.input)), SetOutput(io.Discard))
+		if tc.expErr {
+			if err == nil {
+				t.Errorf("%s: an error was expected but not seen", tc.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: unexpected error creating the responder: %v",
+				tc.name, err)
+		}
+
+		for i, exp := range tc.expResps {
+			if tc.resetAfter != 0 && i == tc.resetAfter {
+				br.Reset()
+			}
+
+			resp, err := br.GetResponse()
+			if err != nil {
+				t.Errorf("%s: response %d: unexpected error: %v",
+					tc.name, i, err)
+			}
+			if resp != exp {
+				t.Errorf("%s: response %d: expected %q, got: %q",
+					tc.name, i, exp, resp)
+			}
+		}
+
+		decision, decided := br.Decision()
+		if decided != tc.expDecided || decision != tc.expDecision {
+			t.Errorf("%s: expected decision %q (%t), got: %q (%t)",
+				tc.name, tc.expDecision, tc.expDecided, decision, decided)
+		}
+	}
+}
+
+func TestGetResponseDeadline(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("a pipe cannot be polled for input on Windows")
+	}
+
+	resps := map[rune]string{
+		'y': "yes",
+		'n': "no",
+	}
+
+	testCases := []struct {
+		name       string
+		input      string
+		opts       []RespOptFunc
+		deadline   time.Duration
+		expResp    rune
+		expExpired bool
+		expErr     error
+	}{
+		{
+			name:     "answered before the deadline",
+			input:    "y",
+			deadline: time.Hour,
+			expResp:  'y',
+		},
+		{
+			name:     "deadline passed, input already waiting",
+			input:    "y",
+			deadline: -time.Second,
+			expResp:  'y',
+		},
+		{
+			name:       "deadline passed, default",
+			opts:       []RespOptFunc{SetDefault('n')},
+			deadline:   -time.Second,
+			expResp:    'n',
+			expExpired: true,
+		},
+		{
+			name:       "deadline passed, no default",
+			deadline:   -time.Second,
+			expResp:    unicode.ReplacementChar,
+			expExpired: true,
+			expErr:     ErrTimedOut,
+		},
+	}
+
+	for _, tc := range testCases {
+		pr, pw, err := os.Pipe()
+		if err != nil {
+			t.Fatalf("%s: cannot create the pipe: %v", tc.name, err)
+		}
+		// the write end is kept open so that a read would block rather
+		// than see the end of the input
+		if _, err = pw.WriteString(tc.input); err != nil {
+			t.Fatalf("%s: cannot write to the pipe: %v", tc.name, err)
+		}
+
+		opts := append([]RespOptFunc{SetFile(pr), SetOutput(io.Discard)},
+			tc.opts...)
+		r, err := New("test", resps, opts...)
+		if err != nil {
+			t.Fatalf("%s: unexpected error creating the responder: %v",
+				tc.name, err)
+		}
+
+		resp, expired, err := r.GetResponseDeadline(
+			time.Now().Add(tc.deadline), 0, 0)
+		if !errors.Is(err, tc.expErr) {
+			t.Errorf("%s: expected error %v, got: %v", tc.name, tc.expErr, err)
+		}
+		if resp != tc.expResp {
+			t.Errorf("%s: expected response %q, got: %q",
+				tc.name, tc.expResp, resp)
+		}
+		if expired != tc.expExpired {
+			t.Errorf("%s: expected the deadline to have passed: %t, got: %t",
+				tc.name, tc.expExpired, expired)
+		}
+
+		pr.Close()
+		pw.Close()
+	}
+}
+
+func TestConfirmAll(t *testing.T) {
+	testCases := []struct {
+		name      string
+		input     string
+		opts      []RespOptFunc
+		expResult ConfirmResult
+		expErr    bool
+	}{
+		{
+			name:      "yes",
+			input:     "y",
+			expResult: ConfirmYes,
+		},
+		{
+			name:      "no",
+			input:     "N",
+			expResult: ConfirmNo,
+		},
+		{
+			name:      "yes to all",
+			input:     "a",
+			expResult: ConfirmYesAll,
+		},
+		{
+			name:      "no to all, after a bad response",
+			input:     "xd",
+			expResult: ConfirmNoAll,
+		},
+		{
+			name:      "default",
+			input:     " ",
+			opts:      []RespOptFunc{SetDefault('a')},
+			expResult: ConfirmYesAll,
+		},
+		{
+			name:      "bad option",
+			input:     "y",
+			opts:      []RespOptFunc{SetDefault('q')},
+			expResult: ConfirmNo,
+			expErr:    true,
+		},
+		{
+			name:      "no input",
+			expResult: ConfirmNo,
+			expErr:    true,
+		},
+	}
+
+	for _, tc := range testCases {
+		opts := append([]RespOptFunc{
+			SetInput(strings.NewReader(tc.input)),
+			SetOutput(io.Discard),
+			SetErrOutput(io.Discard),
+		}, tc.opts...)
+
+		res, err := ConfirmAll("test", opts...)
+		if (err != nil) != tc.expErr {
+			t.Errorf("%s: unexpected error state: %v", tc.name, err)
+		}
+		if res != tc.expResult {
+			t.Errorf("%s: expected %s, got: %s", tc.name, tc.expResult, res)
+		}
+	}
+}
+
+func TestSetTranscript(t *testing.T) {
+	resps := map[rune]string{
+		'y': "yes",
+		'n': "no",
+	}
+	const prompt = `prompt: "test? (n/y/?): "`
+
+	testCases := []struct {
+		name      string
+		input     string
+		opts      []RespOptFunc
+		expEvents []string
+	}{
+		{
+			name:      "answered",
+			input:     "y",
+			expEvents: []string{prompt, "answer: y"},
+		},
+		{
+			name:  "help then answered",
+			input: "?n",
+			expEvents: []string{
+				prompt, "help: ", prompt, "answer: n",
+			},
+		},
+		{
+			name:  "bad response",
+			input: "xy",
+			expEvents: []string{
+				prompt, "error: bad response: x", prompt, "answer: y",
+			},
+		},
+		{
+			name:  "repeated, then the default cycled",
+			input: "r\t ",
+			opts:  []RespOptFunc{SetRepeatKey('r'), SetTabCyclesDefault()},
+			expEvents: []string{
+				prompt,
+				"repeat: ",
+				prompt,
+				"default: n",
+				`prompt: "test? ([n]/y/?): "`,
+				"answer: n",
+			},
+		},
+		{
+			name:  "no input",
+			input: "",
+			expEvents: []string{
+				prompt, "error: EOF",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		var transcript bytes.Buffer
+		opts := append([]RespOptFunc{SetTranscript(&transcript)}, tc.opts...)
+		r, _, err := NewTestResponder(tc.input, resps, opts...)
+		if err != nil {
+			t.Fatalf("%s: unexpected error creating the responder: %v",
+				tc.name, err)
+		}
+
+		_, _ = r.GetResponse()
+
+		lines := strings.Split(strings.TrimSuffix(transcript.String(), "\n"),
+			"\n")
+		events := make([]string, 0, len(lines))
+		for _, line := range lines {
+			ts, event, _ := strings.Cut(line, " ")
+			if _, err := time.Parse(transcriptTimeFmt, ts); err != nil {
+				t.Errorf("%s: bad timestamp on line %q: %v", tc.name, line, err)
+			}
+			events = append(events, event)
+		}
+
+		if strings.Join(events, "\n") != strings.Join(tc.expEvents, "\n") {
+			t.Errorf("%s: expected events:\n%s\ngot:\n%s",
+				tc.name,
+				strings.Join(tc.expEvents, "\n"),
+				strings.Join(events, "\n"))
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		name   string
+		opts   []RespOptFunc
+		change func(r *R)
+		expErr string
+	}{
+		{
+			name:   "unchanged",
+			opts:   []RespOptFunc{SetDefault('n')},
+			change: func(_ *R) {},
+		},
+		{
+			name: "too few responses",
+			change: func(r *R) {
+				delete(r.validResps, 'n')
+			},
+			expErr: "too few allowed responses",
+		},
+		{
+			name: "uppercase response",
+			change: func(r *R) {
+				r.validResps['Q'] = "quit"
+			},
+			expErr: "'Q' is uppercase",
+		},
+		{
+			name: "help rune as a response",
+			change: func(r *R) {
+				r.validResps[helpRune] = "help"
+			},
+			expErr: "it is used to request help",
+		},
+		{
+			name: "default no longer a response",
+			opts: []RespOptFunc{SetDefault('n')},
+			change: func(r *R) {
+				r.dflt = 'q'
+			},
+			expErr: "the default response (q) is not" +
+				" in the list of valid responses",
+		},
+		{
+			name: "abort response no longer a response",
+			opts: []RespOptFunc{SetAbortResponse('n')},
+			change: func(r *R) {
+				r.abortResp = 'q'
+			},
+			expErr: "the abort response (q) is not" +
+				" in the list of valid responses",
+		},
+		{
+			name: "response clashes with a numeric hotkey",
+			opts: []RespOptFunc{SetNumericHotkeys()},
+			change: func(r *R) {
+				r.validResps['1'] = "one"
+			},
+			expErr: "the response '1' is also used as a numeric hotkey",
+		},
+	}
+
+	for _, tc := range testCases {
+		r, err := New("test",
+			map[rune]string{
+				'y': "yes",
+				'n': "no",
+			},
+			tc.opts...)
+		if err != nil {
+			t.Fatalf("%s: unexpected error creating the responder: %v",
+				tc.name, err)
+		}
+
+		tc.change(r)
+
+		err = r.Validate()
+		if tc.expErr == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", tc.name, err)
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("%s: an error was expected but not seen", tc.name)
+		} else if !strings.Contains(err.Error(), tc.expErr) {
+			t.Errorf("%s: expected an error containing %q, got: %v",
+				tc.name, tc.expErr, err)
+		}
+	}
+}
+
+func TestKeyMap(t *testing.T) {
+	resps := map[rune]string{
+		'y': "yes",
+		'n': "no",
+	}
+
+	testCases := []struct {
+		name   string
+		opts   []RespOptFunc
+		expMap map[rune]rune
+	}{
+		{
+			name: "no default",
+			expMap: map[rune]rune{
+				'y': 'y', 'Y': 'y',
+				'n': 'n', 'N': 'n',
+				'?': '?',
+			},
+		},
+		{
+			name: "default",
+			opts: []RespOptFunc{SetDefault('n'), SetNoHelp()},
+			expMap: map[rune]rune{
+				'y': 'y', 'Y': 'y',
+				'n': 'n', 'N': 'n',
+				' ': 'n', '\t': 'n', '\n': 'n', '\r': 'n',
+			},
+		},
+		{
+			name: "default, tab cycles the default",
+			opts: []RespOptFunc{
+				SetDefault('n'),
+				SetNoHelp(),
+				SetTabCyclesDefault(),
+			},
+			expMap: map[rune]rune{
+				'y': 'y', 'Y': 'y',
+				'n': 'n', 'N': 'n',
+				' ': 'n', '\n': 'n', '\r': 'n',
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		r := NewOrPanic("test", resps, tc.opts...)
+
+		km := r.KeyMap()
+		if len(km) != len(tc.expMap) {
+			t.Errorf("%s: expected %d keys, got: %d - %q",
+				tc.name, len(tc.expMap), len(km), km)
+		}
+		for k, exp := range tc.expMap {
+			if got, ok := km[k]; !ok || got != exp {
+				t.Errorf("%s: expected %q to map to %q, got: %q (%t)",
+					tc.name, k, exp, got, ok)
+			}
+		}
+	}
+}
+
+func TestReportDieErr(t *testing.T) {
+	resps := map[rune]string{
+		'y': "yes",
+		'n': "no",
+	}
+
+	testCases := []struct {
+		name   string
+		opts   []RespOptFunc
+		expOut string
+	}{
+		{
+			name:   "default message",
+			opts:   []RespOptFunc{SetIndents(0, 2)},
+			expOut: "\n      oops\n",
+		},
+		{
+			name: "die message",
+			opts: []RespOptFunc{
+				SetDieMessage(func(err error) string {
+					return "fatal: " + err.Error()
+				}),
+			},
+			expOut: "fatal: oops\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		var errOut bytes.Buffer
+		opts := append([]RespOptFunc{SetErrOutput(&errOut)}, tc.opts...)
+		r := NewOrPanic("test", resps, opts...)
+
+		r.reportDieErr(errors.New("oops"))
+		if errOut.String() != tc.expOut {
+			t.Errorf("%s: expected error output %q, got: %q",
+				tc.name, tc.expOut, errOut.String())
+		}
+	}
+}
+
+func TestPromptWidth(t *testing.T) {
+	resps := map[rune]string{
+		'y': "yes",
+		'n': "no",
+	}
+
+	testCases := []struct {
+		name     string
+		prompt   string
+		expWidth int
+	}{
+		{
+			name:     "ascii",
+			prompt:   "test",
+			expWidth: len("test? (n/y/?): "),
+		},
+		{
+			name:     "CJK, two columns per character",
+			prompt:   "削除",
+			expWidth: 4 + len("? (n/y/?): "),
+		},
+		{
+			name:     "combining accent, no columns",
+			prompt:   "cafe\u0301",
+			expWidth: 4 + len("? (n/y/?): "),
+		},
+	}
+
+	for _, tc := range testCases {
+		r := NewOrPanic(tc.prompt, resps)
+
+		if w := r.PromptWidth(); w != tc.expWidth {
+			t.Errorf("%s: expected the width of %q to be %d, got: %d",
+				tc.name, r.PromptString(), tc.expWidth, w)
+		}
+	}
+}
+
+func TestEchoRejectedSplitStreams(t *testing.T) {
+	resps := map[rune]string{
+		'y': "yes",
+		'n': "no",
+	}
+
+	var out, errOut bytes.Buffer
+
+	r := NewOrPanic("test", resps,
+		SetInput(strings.NewReader("xzy")),
+		SetOutput(&out),
+		SetErrOutput(&errOut),
+		SetEchoRejected())
+
+	resp, err := r.GetResponse()
+	if err != nil || resp != 'y' {
+		t.Fatalf("expected 'y' and no error, got: %q, %v", resp, err)
+	}
+
+	const expOut = "test? (n/y/?): x\n" +
+		"test? (n/y/?): z\n" +
+		"test? (n/y/?): "
+	if out.String() != expOut {
+		t.Errorf("expected output: %q, got: %q", expOut, out.String())
+	}
+
+	const expErrOut = "    bad response: x\n" +
+		"    bad response: z\n"
+	if errOut.String() != expErrOut {
+		t.Errorf("expected error output: %q, got: %q",
+			expErrOut, errOut.String())
+	}
+}
+
+func TestAsk(t *testing.T) {
+	choices := map[rune]string{
+		'y': "yes",
+		'n': "no",
+	}
+
+	testCases := []struct {
+		name    string
+		input   string
+		opts    []RespOptFunc
+		expResp rune
+		expDesc string
+		expErr  error
+	}{
+		{
+			name:    "answered",
+			input:   "y",
+			expResp: 'y',
+			expDesc: "yes",
+		},
+		{
+			name:    "default",
+			input:   "\n",
+			opts:    []RespOptFunc{SetDefault('n')},
+			expResp: 'n',
+			expDesc: "no",
+		},
+		{
+			name:    "no response",
+			expResp: unicode.ReplacementChar,
+			expErr:  io.EOF,
+		},
+		{
+			name:   "bad option",
+			opts:   []RespOptFunc{SetDefault('x')},
+			expErr: errAny,
+		},
+	}
+
+	for _, tc := range testCases {
+		opts := append([]RespOptFunc{
+			SetInput(strings.NewReader(tc.input)),
+			SetOutput(io.Discard),
+			SetErrOutput(io.Discard),
+		}, tc.opts...)
+
+		resp, desc, err := Ask("test", choices, opts...)
+		if resp != tc.expResp || desc != tc.expDesc {
+			t.Errorf("%s: expected %q (%q), got: %q (%q)",
+				tc.name, tc.expResp, tc.expDesc, resp, desc)
+		}
+		if tc.expErr == errAny {
+			if err == nil {
+				t.Errorf("%s: expected an error, got none", tc.name)
+			}
+		} else if !errors.Is(err, tc.expErr) {
+			t.Errorf("%s: expected error: %v, got: %v",
+				tc.name, tc.expErr, err)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package responder
+
+import (
+	"fmt"
+	"os"
+	"unicode"
+)
+
+// dfltMaxRetries is the default number of times a Retryable will rebuild
+// and rerun its responder
+const dfltMaxRetries = 10
+
+// Retryable is a Responder which gives "start over" behaviour. It builds a
+// responder and gets a response from it; if the response is the retry
+// response then a new responder is built and the user is prompted again,
+// otherwise the response is returned. The build function is called afresh
+// each time so that it can reset any state for the flow being retried.
+type Retryable struct {
+	build      func() (*R, error)
+	retryOn    rune
+	maxRetries int
+}
+
+// NewRetryable creates a Retryable which will use the build function to
+// make the responder and which will start over whenever the retryOn
+// response is chosen. The retryOn response must be a valid response of
+// every responder built, otherwise an error is returned when the
+// responder is built. The number of retries is limited (see
+// SetMaxRetries) so that the user cannot be trapped retrying forever.
+func NewRetryable(build func() (*R, error), retryOn rune) *Retryable {
+	return &Retryable{
+		build:      build,
+		retryOn:    retryOn,
+		maxRetries: dfltMaxRetries,
+	}
+}
+
+// SetMaxRetries sets the maximum number of times the responder will be
+// rebuilt and rerun. Once the retry response has been chosen this many
+// times choosing it again gives an error. A value less than zero is taken
+// as zero, so the retry response will always give an error. The default
+// is 10.
+func (rr *Retryable) SetMaxRetries(n int) {
+	if n < 0 {
+		n = 0
+	}
+
+	rr.maxRetries = n
+}
+
+// GetResponse builds the responder and gets the response from it,
+// starting over each time the retry response is chosen.
+//
+// If an error is detected the response returned will be the unicode
+// ReplacementChar.
+func (rr Retryable) GetResponse() (rune, error) {
+	resp, _, err := rr.run(func(r *R) (rune, error) {
+		return r.GetResponse()
+	})
+
+	return resp, err
+}
+
+// GetResponseOrDie calls GetResponse to get the response but if there is an
+// error it will print it and exit with status 1.
+func (rr Retryable) GetResponseOrDie() rune {
+	resp, r, err := rr.run(func(r *R) (rune, error) {
+		return r.GetResponse()
+	})
+	if err != nil {
+		rr.reportErrAndExit(r, err)
+	}
+
+	return resp
+}
+
+// GetResponseIndent behaves as GetResponse but the indents are taken from
+// the parameters rather than the responder.
+func (rr Retryable) GetResponseIndent(first, second int) (rune, error) {
+	resp, _, err := rr.run(func(r *R) (rune, error) {
+		return r.GetResponseIndent(first, second)
+	})
+
+	return resp, err
+}
+
+// GetResponseIndentOrDie calls GetResponseIndent to get the response but
+// if there is an error it will print it and exit with status 1.
+func (rr Retryable) GetResponseIndentOrDie(first, second int) rune {
+	resp, r, err := rr.run(func(r *R) (rune, error) {
+		return r.GetResponseIndent(first, second)
+	})
+	if err != nil {
+		rr.reportErrAndExit(r, err)
+	}
+
+	return resp
+}
+
+// run repeatedly builds the responder and calls getResp with it until a
+// response other than the retry response is given, an error is detected
+// or there have been too many retries. It returns the response along with
+// the last responder built, which will be nil if none could be built.
+func (rr Retryable) run(getResp func(*R) (rune, error)) (rune, *R, error) {
+	if rr.build == nil {
+		return unicode.ReplacementChar, nil,
+			fmt.Errorf("the build function must not be nil")
+	}
+
+	var last *R
+
+	for retries := 0; ; retries++ {
+		r, err := rr.build()
+		if err != nil {
+			return unicode.ReplacementChar, last,
+				fmt.Errorf("cannot build the responder: %w", err)
+		}
+		if r == nil {
+			return unicode.ReplacementChar, last,
+				fmt.Errorf("the build function returned a nil responder")
+		}
+		last = r
+
+		if _, ok := r.validResps[rr.retryOn]; !ok {
+			return unicode.ReplacementChar, last,
+				fmt.Errorf("the retry response (%c) is not"+
+					" in the list of valid responses",
+					rr.retryOn)
+		}
+
+		resp, err := getResp(r)
+		if err != nil || resp != rr.retryOn {
+			return resp, last, err
+		}
+
+		if retries >= rr.maxRetries {
+			return unicode.ReplacementChar, last,
+				fmt.Errorf("too many retries - the retry response (%c)"+
+					" was chosen more than %d times",
+					rr.retryOn, rr.maxRetries)
+		}
+	}
+}
+
+// reportErrAndExit reports the error using the responder, if there is
+// one, and exits with status 1
+func (rr Retryable) reportErrAndExit(r *R, err error) {
+	if r != nil {
+		r.reportErrAndExit(err)
+		return
+	}
+
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(errExitStatus)
+}
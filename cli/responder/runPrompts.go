@@ -0,0 +1,155 @@
+package responder
+
+import (
+	"bytes"
+	"io"
+
+	"golang.org/x/term"
+)
+
+// RunPrompts gets a response from each of the responders in turn and
+// returns the responses in the same order. It stops at the first error,
+// returning the responses collected so far and the error.
+//
+// The terminal is put into raw mode once, using the settings of the first
+// responder, and is restored once all the responses have been read rather
+// than for each response. Any responders reading from a different file are
+// handled as usual. While the terminal is in raw mode any newlines written
+// to a terminal output, including the error output, are written as
+// carriage return, newline pairs so that the prompts are laid out as usual.
+//
+// The terminal is restored while the help pager (see SetHelpPager) or the
+// editor (see SetEditorResponse) is running and is put back into raw mode
+// once it has finished.
+func RunPrompts(rs []*R) ([]rune, error) {
+	resps := make([]rune, 0, len(rs))
+	if len(rs) == 0 {
+		return resps, nil
+	}
+
+	sess := &rawSession{fd: rs[0].fd, makeRaw: rs[0].makeRaw}
+	isRaw := false
+	if sess.fd != noFD {
+		restore, err := sess.makeRaw(sess.fd)
+		if err == nil {
+			isRaw = true
+			sess.restore = restore
+			defer sess.end()
+		}
+	}
+
+	for _, r := range rs {
+		rc := *r
+		if isRaw && rc.fd == sess.fd {
+			rc.makeRaw = alreadyRaw
+			rc.rawSess = sess
+			rc.out = crlfWrap(rc.out)
+			rc.errOut = crlfWrap(rc.errOut)
+		}
+
+		resp, err := rc.GetResponse()
+		if err != nil {
+			return resps, err
+		}
+		resps = append(resps, resp)
+	}
+
+	return resps, nil
+}
+
+// rawSession records the state of a terminal which has been put into raw
+// mode for a sequence of prompts (see RunPrompts)
+type rawSession struct {
+	fd      int
+	makeRaw func(fd int) (restore func() error, err error)
+	restore func() error
+}
+
+// suspend restores the terminal and returns a function which will put it
+// back into raw mode
+func (rs *rawSession) suspend() func() {
+	if rs.restore == nil {
+		return func() {}
+	}
+
+	_ = rs.restore()
+	rs.restore = nil
+
+	return func() {
+		if restore, err := rs.makeRaw(rs.fd); err == nil {
+			rs.restore = restore
+		}
+	}
+}
+
+// end restores the terminal if it is still in raw mode
+func (rs *rawSession) end() {
+	if rs.restore != nil {
+		_ = rs.restore()
+		rs.restore = nil
+	}
+}
+
+// suspendRaw takes the terminal out of raw mode if it was put into raw mode
+// by RunPrompts and returns a function which will put it back. Otherwise
+// the terminal is only in raw mode while a key is read and so there is
+// nothing to do.
+func (r R) suspendRaw() func() {
+	if r.rawSess == nil {
+		return func() {}
+	}
+
+	return r.rawSess.suspend()
+}
+
+// alreadyRaw is used in place of the function which puts the terminal into
+// raw mode when the terminal is already in raw mode
+func alreadyRaw(_ int) (func() error, error) {
+	return func() error { return nil }, nil
+}
+
+// crlfWrap returns the writer wrapped in a crlfWriter if it is a terminal,
+// otherwise it returns the writer unchanged
+func crlfWrap(w io.Writer) io.Writer {
+	f, ok := w.(interface{ Fd() uintptr })
+	if !ok || !term.IsTerminal(int(f.Fd())) {
+		return w
+	}
+
+	return crlfWriter{w: w, fd: f.Fd()}
+}
+
+// unwrapCRLF returns the writer underlying a crlfWriter or the writer
+// itself if it is not a crlfWriter
+func unwrapCRLF(w io.Writer) io.Writer {
+	if cw, ok := w.(crlfWriter); ok {
+		return cw.w
+	}
+
+	return w
+}
+
+// crlfWriter writes to the underlying writer replacing each newline with a
+// carriage return, newline pair. This is needed when writing to a terminal
+// in raw mode as the terminal will no longer do this itself. The file
+// descriptor is kept so that the writer is still recognised as a terminal.
+type crlfWriter struct {
+	w  io.Writer
+	fd uintptr
+}
+
+// Write writes the bytes to the underlying writer with any newlines
+// replaced. It returns the number of bytes of p that were written.
+func (cw crlfWriter) Write(p []byte) (int, error) {
+	_, err := cw.w.Write(bytes.ReplaceAll(p, []byte("\n"), []byte("\r\n")))
+	if err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// Fd returns the file descriptor of the underlying writer
+func (cw crlfWriter) Fd() uintptr {
+	return cw.fd
+}
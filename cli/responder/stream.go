@@ -0,0 +1,110 @@
+package responder
+
+import (
+	"context"
+	"io"
+	"time"
+	"unicode"
+)
+
+// streamPollInterval is how often the response stream checks whether its
+// context has been cancelled while waiting for input
+const streamPollInterval = 100 * time.Millisecond
+
+// ResponseEvent records a single response read by ResponseStream
+type ResponseEvent struct {
+	Rune rune
+	Kind Kind
+	Err  error
+}
+
+// ResponseStream reads responses from the responder's input and sends them
+// on the returned channel until the end of the input is reached, a read
+// fails or the context is cancelled; the channel is then closed. Each rune
+// read is classified (see Classify) and sent as a ResponseEvent; invalid
+// responses are sent with the error. A read error other than io.EOF is
+// sent before the channel is closed. No prompt is printed.
+//
+// The terminal is put into raw mode once when the stream starts and is
+// restored when the stream ends.
+//
+// The context is checked while waiting for input only if the input can be
+// polled (see SetInput); otherwise the stream will not notice that the
+// context has been cancelled until the next rune has been read.
+func (r R) ResponseStream(ctx context.Context) <-chan ResponseEvent {
+	ch := make(chan ResponseEvent)
+
+	go func() {
+		defer close(ch)
+
+		send := func(ev ResponseEvent) bool {
+			select {
+			case ch <- ev:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		r, err := r.resolveDefault()
+		if err != nil {
+			send(ResponseEvent{
+				Rune: unicode.ReplacementChar,
+				Kind: KindInvalid,
+				Err:  err,
+			})
+			return
+		}
+
+		if r.fd != noFD {
+			restore, err := r.makeRaw(r.fd)
+			if err == nil {
+				defer restore() //nolint: errcheck
+			}
+		}
+
+		for {
+			if !r.waitForCtx(ctx) {
+				return
+			}
+
+			input, err := r.readRune()
+			if err != nil {
+				if err != io.EOF {
+					send(ResponseEvent{
+						Rune: unicode.ReplacementChar,
+						Kind: KindInvalid,
+						Err:  err,
+					})
+				}
+				return
+			}
+
+			resp, kind, err := r.classifyHelpFirst(input)
+			if !send(ResponseEvent{Rune: resp, Kind: kind, Err: err}) {
+				return
+			}
+		}
+	}()
+
+	return ch
+}
+
+// waitForCtx waits until there is input to read. It returns false if the
+// context is cancelled first. If the input cannot be polled it returns true
+// immediately unless the context has already been cancelled.
+func (r R) waitForCtx(ctx context.Context) bool {
+	if r.fd == noFD || r.rdr.Buffered() > 0 {
+		return ctx.Err() == nil
+	}
+
+	for {
+		ready, err := waitForInput(r.fd, streamPollInterval)
+		if ctx.Err() != nil {
+			return false
+		}
+		if err != nil || ready {
+			return true
+		}
+	}
+}
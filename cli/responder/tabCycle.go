@@ -0,0 +1,70 @@
+package responder
+
+import (
+	"errors"
+	"fmt"
+)
+
+// errCycleDefault is returned by getResp when the user presses Tab and
+// SetTabCyclesDefault has been given
+var errCycleDefault = errors.New("cycle the default response")
+
+// SetTabCyclesDefault makes the Tab key change the default response rather
+// than selecting it. Each press of Tab makes the next response (in sorted
+// order) the default and the prompt is redrawn to show it; pressing Enter,
+// or any other whitespace, selects the default as usual. If there is no
+// default then the first press of Tab makes the first response the
+// default.
+//
+// This applies to GetResponse and the methods which call it, but not to
+// GetOnce or ResponseStream where Tab selects the default as usual. It
+// cannot be used with SetLineMode.
+func SetTabCyclesDefault() RespOptFunc {
+	return func(r *R) error {
+		r.tabCyclesDflt = true
+
+		return nil
+	}
+}
+
+// cycleDefault makes the next response the default, skipping any forbidden
+// defaults (see SetForbidDefault). The default should already have been
+// resolved.
+func (r *R) cycleDefault() {
+	keys := make([]rune, 0, len(r.validResps))
+	for _, k := range r.getSortedValidResponses() {
+		if !r.forbiddenDflts[k] {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == 0 {
+		return
+	}
+
+	next := keys[0]
+	if r.hasDflt {
+		for i, k := range keys {
+			if k == r.dflt {
+				next = keys[(i+1)%len(keys)]
+				break
+			}
+		}
+	}
+
+	r.dflt = next
+	r.hasDflt = true
+	r.hasInterDflt = false
+	r.hasNonInterDflt = false
+}
+
+// redrawForCycle moves to the start of a fresh line for the prompt to be
+// redrawn after the default has changed. On a terminal the current line is
+// cleared, otherwise a newline is printed.
+func (r R) redrawForCycle() {
+	if r.outputIsTerminal() {
+		fmt.Fprint(r.out, clearLineSeq)
+		return
+	}
+
+	fmt.Fprintln(r.out)
+}
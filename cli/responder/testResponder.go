@@ -1,6 +1,10 @@
 package responder
 
-import "os"
+import (
+	"bytes"
+	"os"
+	"strings"
+)
 
 // FixedResponse always returns the given response. This is expected to be
 // useful for testing. Note that there are no checks made of the Response and
@@ -35,3 +39,61 @@ func (fr FixedResponse) GetResponseIndent(_, _ int) (rune, error) {
 func (fr FixedResponse) GetResponseIndentOrDie(_, _ int) rune {
 	return fr.GetResponseOrDie()
 }
+
+// FuncResponder calls the function to get the response. This is expected to
+// be useful for testing where the response needs to vary from one call to
+// the next. As with FixedResponse no checks are made of the response.
+type FuncResponder func() (rune, error)
+
+// GetResponse returns the results of calling the function
+func (f FuncResponder) GetResponse() (rune, error) {
+	return f()
+}
+
+// GetResponseOrDie returns the response from the function. It will exit if
+// the function returns a non-nil error.
+func (f FuncResponder) GetResponseOrDie() rune {
+	resp, err := f()
+	if err != nil {
+		os.Exit(errExitStatus)
+	}
+	return resp
+}
+
+// GetResponseIndent returns the results of calling the function
+func (f FuncResponder) GetResponseIndent(_, _ int) (rune, error) {
+	return f()
+}
+
+// GetResponseIndentOrDie returns the response from the function. It will
+// exit if the function returns a non-nil error.
+func (f FuncResponder) GetResponseIndentOrDie(_, _ int) rune {
+	return f.GetResponseOrDie()
+}
+
+// NewTestResponder creates a responder which reads its responses from the
+// input string and writes the prompt, any help messages and any errors to
+// the returned buffer. This is expected to be useful for testing code which
+// uses a responder. The prompt is "test"; a different prompt can be given
+// with the SetPromptFunc option.
+func NewTestResponder(
+	input string,
+	responses map[rune]string,
+	opts ...RespOptFunc,
+) (*R, *bytes.Buffer, error) {
+	buf := new(bytes.Buffer)
+
+	allOpts := make([]RespOptFunc, 0, len(opts)+3)
+	allOpts = append(allOpts, opts...)
+	allOpts = append(allOpts,
+		SetInput(strings.NewReader(input)),
+		SetOutput(buf),
+		SetErrOutput(buf))
+
+	r, err := New("test", responses, allOpts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return r, buf, nil
+}
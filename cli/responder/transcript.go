@@ -0,0 +1,44 @@
+package responder
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// transcriptTimeFmt is the format of the timestamp on each transcript line
+const transcriptTimeFmt = "2006-01-02T15:04:05.000Z07:00"
+
+// SetTranscript sets a writer to which a transcript of the interaction
+// with the user is written. Each line starts with a timestamp followed by
+// the kind of event and any details. The events are:
+//
+//	prompt: the prompt shown to the user
+//	help: the user asked for help
+//	default: the new default chosen with Tab (see SetTabCyclesDefault)
+//	repeat: the user asked for the prompt to be repeated (see SetRepeatKey)
+//	answer: the valid response given by the user
+//	error: the error reported after an invalid response or a failed read
+//
+// This can be useful for support or for reproducing a problem.
+func SetTranscript(w io.Writer) RespOptFunc {
+	return func(r *R) error {
+		if w == nil {
+			return fmt.Errorf("SetTranscript: the writer must not be nil")
+		}
+
+		r.transcript = w
+
+		return nil
+	}
+}
+
+// record writes an event to the transcript, if there is one
+func (r R) record(event, details string) {
+	if r.transcript == nil {
+		return
+	}
+
+	fmt.Fprintf(r.transcript, "%s %s: %s\n",
+		time.Now().Format(transcriptTimeFmt), event, details)
+}
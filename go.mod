@@ -4,12 +4,10 @@ go 1.18
 
 require (
 	github.com/nickwells/twrap.mod v1.5.4
+	golang.org/x/sys v0.12.0
 	golang.org/x/term v0.12.0
 )
 
 require github.com/nickwells/mathutil.mod/v2 v2.3.0 // indirect
 
-require (
-	golang.org/x/exp v0.0.0-20230905200255-921286631fa9 // indirect
-	golang.org/x/sys v0.12.0 // indirect
-)
+require golang.org/x/exp v0.0.0-20230905200255-921286631fa9 // indirect